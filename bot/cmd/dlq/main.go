@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bot/internal/service"
+	"flag"
+	"fmt"
+	"github.com/joho/godotenv"
+	"infra"
+	"log"
+	"os"
+	"time"
+)
+
+const usage = `usage: dlq [-limit n] inspect|replay
+
+  inspect  lists the requests dead-lettered to the dlq, leaving them in it
+  replay   publishes the requests of the dlq back to the bots, removing them from it
+`
+
+// dlq inspects and replays the requests the bots dead-lettered, such as the malformed ones
+func main() {
+	os.Exit(run())
+}
+
+// run runs the command of the arguments and returns its exit code, so the connection is closed before exiting
+func run() int {
+	limit := flag.Int("limit", 20, "the maximum number of requests to inspect or replay")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 || *limit <= 0 {
+		flag.Usage()
+		return 2
+	}
+
+	if err := godotenv.Load(".env"); err != nil {
+		log.Print("Error loading .env file")
+		return 1
+	}
+
+	manager := infra.NewConnectionManager(infra.URLFromEnv())
+	defer manager.Close()
+
+	deadLetters := service.NewDeadLetterService(manager)
+	if err := deadLetters.Setup(); err != nil {
+		log.Print(err)
+		return 1
+	}
+
+	switch flag.Arg(0) {
+	case "inspect":
+		letters, err := deadLetters.Inspect(*limit)
+		if err != nil {
+			log.Print(err)
+			return 1
+		}
+
+		if len(letters) == 0 {
+			fmt.Println("The dlq is empty")
+			return 0
+		}
+
+		for _, l := range letters {
+			fmt.Printf("%s  %-16s %-8s from %s (x%d)\n  %s\n", l.Time.Format(time.RFC3339), l.RoutingKey, l.Reason, l.Queue, l.Count, l.Body)
+		}
+	case "replay":
+		replayed, err := deadLetters.Replay(*limit)
+		fmt.Printf("%d requests replayed\n", replayed)
+		if err != nil {
+			log.Print(err)
+			return 1
+		}
+	default:
+		flag.Usage()
+		return 2
+	}
+
+	return 0
+}
@@ -1,16 +1,107 @@
 package main
 
 import (
+	"bot/db"
+	"bot/internal/catalog"
+	"bot/internal/provider"
+	"bot/internal/repo"
 	"bot/internal/service"
+	"context"
+	"expvar"
 	"github.com/joho/godotenv"
+	"infra"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	_ "time/tzdata"
 )
 
 func main() {
+	os.Exit(run())
+}
+
+// run runs the bot until it is stopped and returns its exit code, so the deferred closes run before exiting
+func run() int {
 	if err := godotenv.Load(".env"); err != nil {
-		log.Fatal("Error loading .env file")
+		log.Print("Error loading .env file")
+		return 1
+	}
+
+	manager := infra.NewConnectionManager(infra.URLFromEnv())
+	defer manager.Close()
+
+	quoteProvider, err := provider.NewFromEnv()
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+
+	ttl, err := provider.CacheTTLFromEnv()
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+
+	cache := provider.NewCachedProvider(quoteProvider, ttl)
+	expvar.Publish("quote_cache", expvar.Func(func() interface{} {
+		return cache.Stats()
+	}))
+
+	// the expvar counters are served at /debug/vars
+	if addr := os.Getenv("METRICS_ADDR"); addr != "" {
+		go func() {
+			if err := http.ListenAndServe(addr, nil); err != nil {
+				log.Printf("error serving metrics: %s", err)
+			}
+		}()
+	}
+
+	history := provider.NewStooqHistoryProvider()
+
+	interval, err := service.AlertIntervalFromEnv()
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+
+	conn, err := db.NewDatabase()
+	if err != nil {
+		log.Printf("error getting db connection: %s", err)
+		return 1
+	}
+	defer conn.Close()
+
+	alertService := service.NewAlertService(manager, cache, repo.NewAlertRepository(conn), interval)
+	go alertService.Run()
+
+	digestService := service.NewDigestService(manager, cache, history, repo.NewDigestRepository(conn))
+	go digestService.Run()
+
+	symbols, err := catalog.Load(catalog.PathFromEnv())
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	go symbols.Watch()
+
+	pool, err := service.WorkerConfigFromEnv()
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+
+	// on SIGINT or SIGTERM the bot stops consuming, answers the requests in flight and closes the connection
+	// the requests prefetched but not started are requeued for another bot
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	stockService := service.NewStockService(manager, cache, history, symbols, provider.NewStooqFXProvider(cache), pool)
+	if err := stockService.ProcessMessages(ctx); err != nil {
+		log.Print(err)
+		return 1
 	}
 
-	stockService := service.NewStockService()
-	stockService.ProcessMessages()
+	return 0
 }
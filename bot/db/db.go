@@ -0,0 +1,18 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	_ "github.com/lib/pq"
+)
+
+// NewDatabase opens the database of the server with the POSTGRES_* env variables
+func NewDatabase() (*sql.DB, error) {
+	user, password, database, host := os.Getenv("POSTGRES_USER"), os.Getenv("POSTGRES_PASSWORD"), os.Getenv("POSTGRES_DB"), os.Getenv("POSTGRES_HOST")
+
+	ds := fmt.Sprintf("postgresql://%s:%s@%s/%s?sslmode=disable", user, password, host, database)
+
+	return sql.Open("postgres", ds)
+}
@@ -2,6 +2,12 @@ module bot
 
 go 1.20
 
-require github.com/streadway/amqp v1.1.0
+require (
+	github.com/lib/pq v1.10.9
+	github.com/streadway/amqp v1.1.0
+	infra v0.0.0-00010101000000-000000000000
+)
 
-require github.com/joho/godotenv v1.5.1 // indirect
+require github.com/joho/godotenv v1.5.1
+
+replace infra => ../infra
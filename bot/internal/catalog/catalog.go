@@ -0,0 +1,286 @@
+package catalog
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	symbolsFileEnv     = "SYMBOLS_FILE"
+	defaultSymbolsFile = "symbols.csv"
+	// refreshInterval is how often the modification time of the file is checked
+	refreshInterval = time.Minute
+)
+
+// Symbol is a stock code of the catalog, in the stooq notation, with the name of its company
+type Symbol struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Exchange string `json:"exchange,omitempty"`
+}
+
+// Catalog keeps the symbols loaded from a csv file with the code, name and exchange columns
+// it is safe for concurrent use, and Refresh loads the file again when it changes
+type Catalog struct {
+	path string
+
+	mu      sync.RWMutex
+	symbols []*Symbol
+	modTime time.Time
+}
+
+// PathFromEnv reads the path of the catalog from the SYMBOLS_FILE env variable, symbols.csv by default
+func PathFromEnv() string {
+	if path := os.Getenv(symbolsFileEnv); path != "" {
+		return path
+	}
+
+	return defaultSymbolsFile
+}
+
+// Load builds a catalog with the symbols of the csv file
+func Load(path string) (*Catalog, error) {
+	c := &Catalog{path: path}
+	if _, err := c.Refresh(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Refresh loads the file again if it was modified since it was loaded, and reports whether it did
+// the symbols loaded before are kept if the file cannot be read
+func (c *Catalog) Refresh() (bool, error) {
+	info, err := os.Stat(c.path)
+	if err != nil {
+		return false, errors.New(fmt.Sprintf("error reading the symbols file: %s", err))
+	}
+
+	c.mu.RLock()
+	unchanged := info.ModTime().Equal(c.modTime)
+	c.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	f, err := os.Open(c.path)
+	if err != nil {
+		return false, errors.New(fmt.Sprintf("error reading the symbols file: %s", err))
+	}
+	defer f.Close()
+
+	symbols, err := parse(f)
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	c.symbols = symbols
+	c.modTime = info.ModTime()
+	c.mu.Unlock()
+
+	return true, nil
+}
+
+// Watch refreshes the catalog every minute, so the file can be edited without restarting the bot, it never returns
+func (c *Catalog) Watch() {
+	for range time.Tick(refreshInterval) {
+		refreshed, err := c.Refresh()
+		if err != nil {
+			log.Printf("error refreshing the symbols catalog: %s", err)
+			continue
+		}
+		if refreshed {
+			log.Printf("Symbols catalog refreshed: %d symbols", c.Len())
+		}
+	}
+}
+
+// Len returns the number of symbols of the catalog
+func (c *Catalog) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.symbols)
+}
+
+// Search returns up to limit symbols matching the query by ticker or company name, best matches first
+// tickers match exactly, by prefix or with a typo, and names by the prefix of their words or anywhere
+func (c *Catalog) Search(query string, limit int) []*Symbol {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	type match struct {
+		symbol *Symbol
+		score  int
+	}
+
+	var matches []match
+	for _, s := range c.symbols {
+		if score := s.score(query); score > 0 {
+			matches = append(matches, match{symbol: s, score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	symbols := make([]*Symbol, len(matches))
+	for i, m := range matches {
+		symbols[i] = m.symbol
+	}
+
+	return symbols
+}
+
+// Suggest returns up to limit symbols close to a stock code that was not found, such as aapl.us for aapl or apple
+func (c *Catalog) Suggest(code string, limit int) []*Symbol {
+	suggestions := c.Search(code, limit)
+
+	// a code with the wrong suffix is searched again without it, such as sap.us for sap.de
+	if ticker, _, ok := strings.Cut(code, "."); ok && len(suggestions) < limit {
+		for _, s := range c.Search(ticker, limit) {
+			if len(suggestions) < limit && !contains(suggestions, s) && s.Code != strings.ToLower(code) {
+				suggestions = append(suggestions, s)
+			}
+		}
+	}
+
+	return suggestions
+}
+
+// score rates how well the symbol matches a lower case query, 0 means it does not match
+func (s *Symbol) score(query string) int {
+	ticker, _, _ := strings.Cut(s.Code, ".")
+	queryTicker, _, _ := strings.Cut(query, ".")
+	name := strings.ToLower(s.Name)
+
+	switch {
+	case s.Code == query:
+		return 100
+	case ticker == query:
+		return 90
+	case strings.HasPrefix(s.Code, query):
+		return 80
+	case strings.HasPrefix(name, query):
+		return 70
+	case hasWordPrefix(name, query):
+		return 60
+	case len(query) >= 3 && strings.Contains(name, query):
+		return 50
+	case len(queryTicker) >= 3 && distance(ticker, queryTicker) == 1:
+		return 40
+	case len(queryTicker) >= 5 && distance(ticker, queryTicker) == 2:
+		return 30
+	default:
+		return 0
+	}
+}
+
+// hasWordPrefix reports whether any word of the name starts with the query
+func hasWordPrefix(name string, query string) bool {
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool { return r == ' ' || r == '-' || r == '.' }) {
+		if strings.HasPrefix(word, query) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// distance is the levenshtein distance between two strings
+func distance(a string, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+// min returns the smallest of the values
+func min(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
+
+// contains reports whether the symbol is in the list
+func contains(symbols []*Symbol, s *Symbol) bool {
+	for _, other := range symbols {
+		if other == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parse reads the symbols of a csv with a code, name and exchange header
+func parse(r io.Reader) ([]*Symbol, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("error parsing the symbols file: %s", err))
+	}
+	if len(records) == 0 {
+		return nil, errors.New("error parsing the symbols file: missing header")
+	}
+
+	var symbols []*Symbol
+	for i, record := range records[1:] {
+		if len(record) < 2 || strings.TrimSpace(record[0]) == "" {
+			return nil, errors.New(fmt.Sprintf("error parsing the symbols file: invalid line %d", i+2))
+		}
+
+		s := &Symbol{
+			Code: strings.ToLower(strings.TrimSpace(record[0])),
+			Name: strings.TrimSpace(record[1]),
+		}
+		if len(record) > 2 {
+			s.Exchange = strings.TrimSpace(record[2])
+		}
+
+		symbols = append(symbols, s)
+	}
+
+	return symbols, nil
+}
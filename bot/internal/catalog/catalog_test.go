@@ -0,0 +1,103 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const symbolsCSV = `code,name,exchange
+aapl.us,Apple Inc.,NASDAQ
+msft.us,Microsoft Corporation,NASDAQ
+sap.de,SAP SE,XETRA
+sap.us,SAP SE ADR,NYSE
+vod.uk,Vodafone Group plc,LSE
+`
+
+func newCatalog(t *testing.T, content string) (*Catalog, string) {
+	path := filepath.Join(t.TempDir(), "symbols.csv")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write the symbols file: %s", err)
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return c, path
+}
+
+func codes(symbols []*Symbol) []string {
+	var codes []string
+	for _, s := range symbols {
+		codes = append(codes, s.Code)
+	}
+	return codes
+}
+
+func TestSearch(t *testing.T) {
+	c, _ := newCatalog(t, symbolsCSV)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"AAPL", []string{"aapl.us"}},
+		{"apple", []string{"aapl.us"}},
+		{"sap", []string{"sap.de", "sap.us"}},
+		{"vodafone", []string{"vod.uk"}},
+		{"corporation", []string{"msft.us"}},
+		{"msfy", []string{"msft.us"}},
+		{"zzzz", nil},
+	}
+
+	for _, tt := range tests {
+		got := codes(c.Search(tt.query, 5))
+		if len(got) != len(tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	c, _ := newCatalog(t, symbolsCSV)
+
+	// the wrong suffix is searched again without it
+	if got := codes(c.Suggest("vod.us", 3)); len(got) != 1 || got[0] != "vod.uk" {
+		t.Errorf("Suggest(vod.us) = %v, want [vod.uk]", got)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	c, path := newCatalog(t, symbolsCSV)
+
+	refreshed, err := c.Refresh()
+	if err != nil || refreshed {
+		t.Fatalf("expected no refresh of an unchanged file, got %v, %v", refreshed, err)
+	}
+
+	if err := os.WriteFile(path, []byte("code,name\nnvda.us,NVIDIA Corporation\n"), 0o644); err != nil {
+		t.Fatalf("failed to write the symbols file: %s", err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatalf("failed to touch the symbols file: %s", err)
+	}
+
+	refreshed, err = c.Refresh()
+	if err != nil || !refreshed {
+		t.Fatalf("expected a refresh of the modified file, got %v, %v", refreshed, err)
+	}
+	if c.Len() != 1 {
+		t.Errorf("expected 1 symbol, got %d", c.Len())
+	}
+}
@@ -0,0 +1,169 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a standard five field cron expression: minute, hour, day of month, month and day of week
+// fields accept *, numbers, ranges such as 1-5, lists such as 1,15 and steps such as */15 or 9-17/2
+// days of week go from 0 (Sunday) to 6, and 7 is also Sunday
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// when both days are restricted a time matches either of them, as in the classic cron
+	domAny, dowAny bool
+}
+
+// field is the range of values of a cron field
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = []field{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// Parse parses a cron expression such as "30 9 * * 1-5"
+func Parse(expr string) (*Schedule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return nil, errors.New(fmt.Sprintf("invalid cron expression %q: expected %d fields", expr, len(fields)))
+	}
+
+	var bits [5]uint64
+	for i, f := range fields {
+		b, err := parseField(parts[i], f)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("invalid cron expression %q: %s", expr, err))
+		}
+		bits[i] = b
+	}
+
+	// 7 is an alias of Sunday
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+
+	return &Schedule{
+		minute: bits[0],
+		hour:   bits[1],
+		dom:    bits[2],
+		month:  bits[3],
+		dow:    bits[4],
+		domAny: parts[2] == "*",
+		dowAny: parts[4] == "*",
+	}, nil
+}
+
+// Matches reports whether the schedule runs at the minute of t, in the location of t
+func (s *Schedule) Matches(t time.Time) bool {
+	return has(s.minute, t.Minute()) && has(s.hour, t.Hour()) && has(s.month, int(t.Month())) && s.matchesDay(t)
+}
+
+// Next returns the first minute after t the schedule runs at, in the location of t
+// it returns the zero time if the schedule never runs, such as on February 30
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	// five years cover every combination of days of month and week
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case !has(s.month, int(t.Month())) || !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !has(s.hour, t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case !has(s.minute, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// matchesDay matches the day of month and the day of week of t
+func (s *Schedule) matchesDay(t time.Time) bool {
+	dom, dow := has(s.dom, t.Day()), has(s.dow, int(t.Weekday()))
+
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dow
+	case s.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// parseField parses a comma separated list of values, ranges and steps into a bit set
+func parseField(expr string, f field) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(expr, ",") {
+		rng, stepExpr, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepExpr); err != nil || step <= 0 {
+				return 0, errors.New(fmt.Sprintf("invalid step %q in the %s field", stepExpr, f.name))
+			}
+		}
+
+		lo, hi := f.min, f.max
+		if rng != "*" {
+			first, last, isRange := strings.Cut(rng, "-")
+
+			var err error
+			if lo, err = parseValue(first, f); err != nil {
+				return 0, err
+			}
+
+			hi = lo
+			if isRange {
+				if hi, err = parseValue(last, f); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				hi = f.max
+			}
+
+			if lo > hi {
+				return 0, errors.New(fmt.Sprintf("invalid range %q in the %s field", rng, f.name))
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+
+	return bits, nil
+}
+
+// parseValue parses a number within the range of the field
+func parseValue(s string, f field) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, errors.New(fmt.Sprintf("invalid value %q in the %s field, expected %d to %d", s, f.name, f.min, f.max))
+	}
+
+	return v, nil
+}
+
+// has reports whether the bit of the value is set
+func has(bits uint64, v int) bool {
+	return bits&(1<<v) != 0
+}
@@ -0,0 +1,68 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("expected an error parsing %q", expr)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	s, err := Parse("30 9 * * 1-5")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2026, 10, 16, 9, 30, 0, 0, ny), true},               // Friday
+		{time.Date(2026, 10, 17, 9, 30, 0, 0, ny), false},              // Saturday
+		{time.Date(2026, 10, 16, 9, 31, 0, 0, ny), false},              // another minute
+		{time.Date(2026, 10, 16, 13, 30, 0, 0, time.UTC).In(ny), true}, // 09:30 EDT
+		{time.Date(2026, 10, 16, 13, 30, 0, 0, time.UTC), false},       // 13:30 UTC
+	}
+
+	for _, tt := range tests {
+		if got := s.Matches(tt.at); got != tt.want {
+			t.Errorf("Matches(%s) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"30 9 * * 1-5", time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC), time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 10, 16, 9, 31, 12, 0, time.UTC), time.Date(2026, 10, 16, 9, 45, 0, 0, time.UTC)},
+		{"0 0 1 1 *", time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC), time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC), time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC), time.Time{}},
+	}
+
+	for _, tt := range tests {
+		s, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("unexpected error parsing %q: %s", tt.expr, err)
+		}
+
+		if got := s.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("%q Next(%s) = %s, want %s", tt.expr, tt.from, got, tt.want)
+		}
+	}
+}
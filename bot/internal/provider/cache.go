@@ -0,0 +1,187 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	cacheTTLEnv     = "QUOTE_CACHE_TTL"
+	defaultCacheTTL = 30 * time.Second
+)
+
+// CacheStats are the counters of a CachedProvider, Coalesced counts the misses that waited for a lookup in flight
+type CacheStats struct {
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Coalesced int64 `json:"coalesced"`
+}
+
+// CachedProvider caches the quotes of a provider by lower case code for a ttl
+// concurrent lookups of the same code share a single upstream request, and the expired quotes are swept once per ttl
+type CachedProvider struct {
+	provider QuoteProvider
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]*cacheEntry
+	inflight  map[string]*lookup
+	nextSweep time.Time
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	coalesced atomic.Int64
+}
+
+type cacheEntry struct {
+	quote   *Quote
+	expires time.Time
+}
+
+// lookup is an upstream request in flight for a code, done is closed when its result is set
+type lookup struct {
+	done  chan struct{}
+	quote *Quote
+	err   error
+}
+
+// CacheTTLFromEnv reads the cache ttl from the QUOTE_CACHE_TTL env variable, such as 30s
+func CacheTTLFromEnv() (time.Duration, error) {
+	value := os.Getenv(cacheTTLEnv)
+	if value == "" {
+		return defaultCacheTTL, nil
+	}
+
+	ttl, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, errors.New(fmt.Sprintf("invalid %s: %s", cacheTTLEnv, err))
+	}
+
+	return ttl, nil
+}
+
+// NewCachedProvider builds a cache in front of the provider, only the quotes fetched successfully are cached
+func NewCachedProvider(p QuoteProvider, ttl time.Duration) *CachedProvider {
+	return &CachedProvider{
+		provider: p,
+		ttl:      ttl,
+		now:      time.Now,
+		entries:  make(map[string]*cacheEntry),
+		inflight: make(map[string]*lookup),
+	}
+}
+
+func (p *CachedProvider) Name() string {
+	return p.provider.Name()
+}
+
+// GetQuotes answers the cached codes, waits for the codes already being looked up
+// and fetches the rest with a single upstream request
+func (p *CachedProvider) GetQuotes(codes []string) ([]*Quote, error) {
+	lookups := make([]*lookup, len(codes))
+	owned := make(map[string]*lookup)
+	var fetch []string
+
+	p.mu.Lock()
+	now := p.now()
+	p.sweep(now)
+	for i, code := range codes {
+		key := strings.ToLower(code)
+
+		if e, ok := p.entries[key]; ok && now.Before(e.expires) {
+			p.hits.Add(1)
+			lookups[i] = &lookup{quote: e.quote}
+			continue
+		}
+
+		p.misses.Add(1)
+
+		if l, ok := p.inflight[key]; ok {
+			p.coalesced.Add(1)
+			lookups[i] = l
+			continue
+		}
+
+		l := &lookup{done: make(chan struct{})}
+		p.inflight[key] = l
+		owned[key] = l
+		lookups[i] = l
+		fetch = append(fetch, key)
+	}
+	p.mu.Unlock()
+
+	if len(fetch) > 0 {
+		p.fetch(fetch, owned)
+	}
+
+	quotes := make([]*Quote, len(codes))
+	for i, l := range lookups {
+		if l.done != nil {
+			<-l.done
+		}
+		if l.err != nil {
+			return nil, l.err
+		}
+
+		quote := *l.quote
+		quotes[i] = &quote
+	}
+
+	return quotes, nil
+}
+
+// Stats returns the current counters of the cache
+func (p *CachedProvider) Stats() CacheStats {
+	return CacheStats{
+		Hits:      p.hits.Load(),
+		Misses:    p.misses.Load(),
+		Coalesced: p.coalesced.Load(),
+	}
+}
+
+// sweep deletes the expired quotes, at most once per ttl, so the codes no longer requested do not stay cached
+// it must be called with the lock held
+func (p *CachedProvider) sweep(now time.Time) {
+	if now.Before(p.nextSweep) {
+		return
+	}
+
+	for key, e := range p.entries {
+		if !now.Before(e.expires) {
+			delete(p.entries, key)
+		}
+	}
+
+	p.nextSweep = now.Add(p.ttl)
+}
+
+// fetch requests the codes to the upstream provider and hands the result to everyone waiting for them
+func (p *CachedProvider) fetch(codes []string, owned map[string]*lookup) {
+	quotes, err := p.provider.GetQuotes(codes)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	expires := p.now().Add(p.ttl)
+	for i, key := range codes {
+		l := owned[key]
+
+		if err != nil {
+			l.err = err
+		} else {
+			l.quote = quotes[i]
+			if quotes[i].Err == nil {
+				p.entries[key] = &cacheEntry{quote: quotes[i], expires: expires}
+			}
+		}
+
+		delete(p.inflight, key)
+		close(l.done)
+	}
+}
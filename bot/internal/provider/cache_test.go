@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// gatedProvider blocks every request until the gate is closed, counting the requests
+type gatedProvider struct {
+	*FakeProvider
+	gate chan struct{}
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (p *gatedProvider) GetQuotes(codes []string) ([]*Quote, error) {
+	p.mu.Lock()
+	p.calls++
+	p.mu.Unlock()
+
+	<-p.gate
+	return p.FakeProvider.GetQuotes(codes)
+}
+
+func TestCachedProviderCoalescesConcurrentLookups(t *testing.T) {
+	upstream := &gatedProvider{FakeProvider: NewFakeProvider(nil), gate: make(chan struct{})}
+	cache := NewCachedProvider(upstream, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cache.GetQuotes([]string{"AAPL.US"}); err != nil {
+				t.Errorf("Expected the quote, got %s", err)
+			}
+		}()
+	}
+
+	// wait until every lookup is either in flight or waiting for it
+	for cache.Stats().Misses < 10 {
+		time.Sleep(time.Millisecond)
+	}
+	close(upstream.gate)
+	wg.Wait()
+
+	if upstream.calls != 1 {
+		t.Errorf("Expected a single upstream request, got %d", upstream.calls)
+	}
+
+	stats := cache.Stats()
+	if stats.Coalesced != 9 {
+		t.Errorf("Expected 9 coalesced lookups, got %d", stats.Coalesced)
+	}
+
+	if _, err := cache.GetQuotes([]string{"aapl.us"}); err != nil {
+		t.Fatalf("Expected the cached quote, got %s", err)
+	}
+	if cache.Stats().Hits != 1 || upstream.calls != 1 {
+		t.Errorf("Expected the quote to be answered from the cache, got %+v", cache.Stats())
+	}
+}
+
+func TestCachedProviderExpiresQuotes(t *testing.T) {
+	upstream := NewFakeProvider(nil)
+	cache := NewCachedProvider(upstream, time.Minute)
+
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	cache.GetQuotes([]string{"aapl.us", "xyz.us"})
+	cache.GetQuotes([]string{"aapl.us", "xyz.us"})
+
+	// the quotes not found are not cached
+	if len(upstream.Requests) != 2 || len(upstream.Requests[1]) != 1 {
+		t.Errorf("Expected only the missing code to be requested again, got %v", upstream.Requests)
+	}
+
+	now = now.Add(2 * time.Minute)
+	cache.GetQuotes([]string{"aapl.us"})
+
+	if len(upstream.Requests) != 3 {
+		t.Errorf("Expected the expired quote to be requested again, got %v", upstream.Requests)
+	}
+}
+
+func TestCachedProviderSweepsExpiredQuotes(t *testing.T) {
+	upstream := NewFakeProvider(map[string]*Quote{
+		"aapl.us": {Symbol: "AAPL.US", Close: 178.85},
+		"msft.us": {Symbol: "MSFT.US", Close: 330.5},
+	})
+	cache := NewCachedProvider(upstream, time.Minute)
+
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	cache.GetQuotes([]string{"aapl.us"})
+
+	// a lookup of another code once the ttl elapsed removes the quotes no longer requested
+	now = now.Add(2 * time.Minute)
+	cache.GetQuotes([]string{"msft.us"})
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if _, ok := cache.entries["aapl.us"]; ok || len(cache.entries) != 1 {
+		t.Errorf("Expected only the quote of msft.us to be cached, got %v", cache.entries)
+	}
+}
@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Currency is the currency a market quotes its prices in, and how to format them
+type Currency struct {
+	Code   string
+	Symbol string
+	// Suffix places the symbol after the value, such as 120.50 zł
+	Suffix   bool
+	Decimals int
+	// Base is the currency exchange rates are quoted for, and Scale the value of a unit in it
+	// such as GBP and 0.01 for the prices of the London listings, quoted in pence
+	Base  string
+	Scale float64
+}
+
+var currencies = map[string]*Currency{
+	"USD": {Code: "USD", Symbol: "$", Decimals: 2},
+	"EUR": {Code: "EUR", Symbol: "€", Decimals: 2},
+	"GBP": {Code: "GBP", Symbol: "£", Decimals: 2},
+	"GBX": {Code: "GBX", Symbol: "p", Suffix: true, Decimals: 2, Base: "GBP", Scale: 0.01},
+	"JPY": {Code: "JPY", Symbol: "¥", Decimals: 0},
+	"HKD": {Code: "HKD", Symbol: "HK$", Decimals: 2},
+	"PLN": {Code: "PLN", Symbol: " zł", Suffix: true, Decimals: 2},
+	"CHF": {Code: "CHF", Symbol: "CHF ", Decimals: 2},
+	"CAD": {Code: "CAD", Symbol: "C$", Decimals: 2},
+}
+
+// marketCurrencies maps the market suffix of the stooq codes to the currency of their listings
+var marketCurrencies = map[string]string{
+	"us": "USD",
+	"uk": "GBX",
+	"de": "EUR",
+	"jp": "JPY",
+	"hk": "HKD",
+	"pl": "PLN",
+}
+
+// defaultCurrency is the currency of the codes without a known market suffix
+const defaultCurrency = "USD"
+
+// LookupCurrency returns the currency with the given code, such as usd or EUR
+func LookupCurrency(code string) (*Currency, bool) {
+	c, ok := currencies[strings.ToUpper(code)]
+	return c, ok
+}
+
+// CurrencyOf returns the currency of the listing of a stock code, by its market suffix
+func CurrencyOf(code string) *Currency {
+	_, market, _ := strings.Cut(strings.ToLower(code), ".")
+
+	if c, ok := marketCurrencies[market]; ok {
+		return currencies[c]
+	}
+
+	return currencies[defaultCurrency]
+}
+
+// Format formats a value with the symbol and precision of the currency, such as $178.85, ¥2350 or 95.20p, without thousands separators
+func (c *Currency) Format(value float64) string {
+	amount := fmt.Sprintf("%.*f", c.Decimals, value)
+	if c.Suffix {
+		return amount + c.Symbol
+	}
+
+	return c.Symbol + amount
+}
+
+// base returns the currency exchange rates are quoted for, and the value of a unit in it
+func (c *Currency) base() (string, float64) {
+	if c.Base == "" {
+		return c.Code, 1
+	}
+
+	return c.Base, c.Scale
+}
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"strings"
+	"sync"
+)
+
+const fakeSource = "fake"
+
+// FakeProvider is a deterministic provider for tests and local development
+// it answers the quotes it was built with, and any other code as not found
+type FakeProvider struct {
+	Quotes map[string]*Quote
+	// Err makes every request fail, simulating an outage of the source
+	Err error
+	// Requests records the codes requested in every call
+	Requests [][]string
+
+	mu sync.Mutex
+}
+
+// NewFakeProvider builds a fake provider answering the given quotes, by lower case code
+// without quotes it answers a fixed quote for aapl.us
+func NewFakeProvider(quotes map[string]*Quote) *FakeProvider {
+	if quotes == nil {
+		quotes = map[string]*Quote{
+			"aapl.us": {Symbol: "AAPL.US", Date: "2023-10-16", Time: "22:00:00", Open: 176.75, High: 179.08, Low: 176.51, Close: 178.85, Volume: 52517010},
+		}
+	}
+
+	return &FakeProvider{
+		Quotes: quotes,
+	}
+}
+
+func (p *FakeProvider) Name() string {
+	return fakeSource
+}
+
+// GetQuotes answers copies of the configured quotes, or the configured error, it is safe for concurrent use
+func (p *FakeProvider) GetQuotes(codes []string) ([]*Quote, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.Requests = append(p.Requests, codes)
+
+	if p.Err != nil {
+		return nil, p.Err
+	}
+
+	quotes := make([]*Quote, 0, len(codes))
+	for _, code := range codes {
+		q, ok := p.Quotes[strings.ToLower(code)]
+		if !ok {
+			quotes = append(quotes, (&Quote{Symbol: strings.ToUpper(code), Source: fakeSource}).fail(ErrStockNotFound))
+			continue
+		}
+
+		quote := *q
+		quote.Source = fakeSource
+		quotes = append(quotes, &quote)
+	}
+
+	return quotes, nil
+}
@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FXProvider fetches the exchange rates between currencies
+type FXProvider interface {
+	// Name identifies the provider as the source of its rates
+	Name() string
+	// GetRate returns the value of a unit of the from currency in the to currency, both in ISO codes such as EUR and USD
+	GetRate(from string, to string) (float64, error)
+}
+
+// Convert converts a value between currencies, including the currencies quoted in fractions of another one such as GBX
+func Convert(p FXProvider, value float64, from *Currency, to *Currency) (float64, error) {
+	fromBase, fromScale := from.base()
+	toBase, toScale := to.base()
+
+	rate := 1.0
+	if fromBase != toBase {
+		var err error
+		if rate, err = p.GetRate(fromBase, toBase); err != nil {
+			return 0, err
+		}
+	}
+
+	return value * fromScale * rate / toScale, nil
+}
+
+type stooqFXProvider struct {
+	quotes QuoteProvider
+}
+
+// NewStooqFXProvider builds an fx provider reading the stooq currency pairs, such as eurusd, as quotes
+func NewStooqFXProvider(quotes QuoteProvider) FXProvider {
+	return &stooqFXProvider{quotes: quotes}
+}
+
+func (p *stooqFXProvider) Name() string {
+	return stooqSource
+}
+
+// GetRate fetches the close of the currency pair
+func (p *stooqFXProvider) GetRate(from string, to string) (float64, error) {
+	pair := strings.ToLower(from + to)
+
+	quotes, err := p.quotes.GetQuotes([]string{pair})
+	if err != nil {
+		return 0, err
+	}
+	if len(quotes) != 1 {
+		return 0, fmt.Errorf("%w: expected the quote of %s", ErrMalformed, pair)
+	}
+	if quotes[0].Err != nil {
+		return 0, quotes[0].Err
+	}
+	if quotes[0].Close <= 0 {
+		return 0, fmt.Errorf("%w: invalid rate of %s", ErrMalformed, pair)
+	}
+
+	return quotes[0].Close, nil
+}
+
+// FakeFXProvider answers fixed rates by pair, such as EURUSD, and their inverse
+type FakeFXProvider struct {
+	Rates map[string]float64
+}
+
+// NewFakeFXProvider builds a fake fx provider answering the given rates
+func NewFakeFXProvider(rates map[string]float64) *FakeFXProvider {
+	return &FakeFXProvider{Rates: rates}
+}
+
+func (p *FakeFXProvider) Name() string {
+	return fakeSource
+}
+
+// GetRate answers the rate of the pair, or the inverse of the rate of the opposite pair
+func (p *FakeFXProvider) GetRate(from string, to string) (float64, error) {
+	if rate, ok := p.Rates[strings.ToUpper(from+to)]; ok {
+		return rate, nil
+	}
+	if rate, ok := p.Rates[strings.ToUpper(to+from)]; ok && rate != 0 {
+		return 1 / rate, nil
+	}
+
+	return 0, fmt.Errorf("%w: no rate for %s%s", ErrStockNotFound, from, to)
+}
@@ -0,0 +1,21 @@
+package provider
+
+import (
+	"time"
+)
+
+// Bar is the daily OHLCV bar of a stock
+type Bar struct {
+	Date   string  `json:"date"`
+	Open   float64 `json:"open"`
+	High   float64 `json:"high"`
+	Low    float64 `json:"low"`
+	Close  float64 `json:"close"`
+	Volume int64   `json:"volume"`
+}
+
+// HistoryProvider fetches the daily price history of a stock code
+type HistoryProvider interface {
+	// GetHistory returns the daily bars of the code between the dates, oldest first
+	GetHistory(code string, from time.Time, to time.Time) ([]*Bar, error)
+}
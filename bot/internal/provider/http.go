@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+const (
+	httpTimeout    = 5 * time.Second
+	httpRetries    = 3
+	httpRetryDelay = 200 * time.Millisecond
+	maxBodySize    = 1 << 20
+)
+
+// httpClient requests the quote providers with a timeout, retrying the failed requests with exponential backoff and jitter
+type httpClient struct {
+	client  *http.Client
+	retries int
+	delay   time.Duration
+	sleep   func(time.Duration)
+}
+
+// newHTTPClient builds a client with the default timeout and retries
+func newHTTPClient() *httpClient {
+	return &httpClient{
+		client:  &http.Client{Timeout: httpTimeout},
+		retries: httpRetries,
+		delay:   httpRetryDelay,
+		sleep:   time.Sleep,
+	}
+}
+
+// get requests the url and returns the status code and body of the response
+// network errors, server errors and rate limits are retried, and classified as ErrUnavailable or ErrRateLimited when they persist
+// any other status code is returned to the caller, which knows what it means for its provider
+func (c *httpClient) get(url string, header http.Header) (int, []byte, error) {
+	var err error
+
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		if attempt > 0 {
+			c.sleep(c.backoff(attempt))
+		}
+
+		var status int
+		var body []byte
+
+		status, body, err = c.do(url, header)
+		if errors.Is(err, ErrUnavailable) {
+			continue
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch {
+		case status == http.StatusTooManyRequests:
+			err = fmt.Errorf("%w: status %d", ErrRateLimited, status)
+		case status >= http.StatusInternalServerError:
+			err = fmt.Errorf("%w: status %d", ErrUnavailable, status)
+		default:
+			return status, body, nil
+		}
+	}
+
+	return 0, nil, err
+}
+
+// do sends a single request and reads its body
+func (c *httpClient) do(url string, header http.Header) (int, []byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return 0, nil, errors.New(fmt.Sprintf("error building request: %s", err))
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: %s", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: error reading response: %s", ErrUnavailable, err)
+	}
+
+	return resp.StatusCode, body, nil
+}
+
+// backoff doubles the delay on every attempt, adding up to one delay of jitter so retries of concurrent requests spread out
+func (c *httpClient) backoff(attempt int) time.Duration {
+	d := c.delay << (attempt - 1)
+	return d + time.Duration(rand.Int63n(int64(c.delay)+1))
+}
@@ -0,0 +1,153 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// Quote is the OHLCV quote of a stock code, or the error getting it
+type Quote struct {
+	Symbol string  `json:"symbol"`
+	Date   string  `json:"date,omitempty"`
+	Time   string  `json:"time,omitempty"`
+	Open   float64 `json:"open"`
+	High   float64 `json:"high"`
+	Low    float64 `json:"low"`
+	Close  float64 `json:"close"`
+	Volume int64   `json:"volume"`
+	Source string  `json:"source,omitempty"`
+	Error  string  `json:"error,omitempty"`
+
+	// Currency is the currency of the prices, and Rate the exchange rate they were converted with
+	// from the currency of the listing, or 0 if they are in the currency of the listing
+	Currency        string  `json:"currency,omitempty"`
+	Rate            float64 `json:"rate,omitempty"`
+	ListingCurrency string  `json:"listingCurrency,omitempty"`
+
+	Err error `json:"-"`
+}
+
+// QuoteProvider fetches the quotes of stock codes from a market data source
+// codes use the stooq notation, such as aapl.us, and providers translate them to their own symbols
+type QuoteProvider interface {
+	// Name identifies the provider in the configuration and as the source of its quotes
+	Name() string
+	// GetQuotes returns a quote for every code in the order they were requested, with the codes that failed
+	// carrying their error, or an error if the provider could not be reached at all
+	GetQuotes(codes []string) ([]*Quote, error)
+}
+
+// the errors of the quotes and providers are classified with these errors, so the bot can explain each failure
+var (
+	ErrStockNotFound = errors.New("stock code not found")
+	ErrUnavailable   = errors.New("quote provider unavailable")
+	ErrRateLimited   = errors.New("quote provider rate limited")
+	ErrMalformed     = errors.New("malformed quote provider response")
+)
+
+const (
+	providersEnv     = "QUOTE_PROVIDERS"
+	defaultProviders = "stooq,yahoo"
+)
+
+// NewFromEnv builds the providers listed in the QUOTE_PROVIDERS env variable, in fallback order
+func NewFromEnv() (QuoteProvider, error) {
+	names := os.Getenv(providersEnv)
+	if names == "" {
+		names = defaultProviders
+	}
+
+	var providers []QuoteProvider
+	for _, name := range strings.Split(names, ",") {
+		switch strings.TrimSpace(strings.ToLower(name)) {
+		case stooqSource:
+			providers = append(providers, NewStooqProvider())
+		case yahooSource:
+			providers = append(providers, NewYahooProvider())
+		case fakeSource:
+			providers = append(providers, NewFakeProvider(nil))
+		default:
+			return nil, errors.New(fmt.Sprintf("unknown quote provider: %q", name))
+		}
+	}
+
+	return NewFallbackProvider(providers...), nil
+}
+
+type fallbackProvider struct {
+	providers []QuoteProvider
+}
+
+// NewFallbackProvider builds a provider that asks the providers in order
+// the codes a provider fails to quote are asked to the next one, so an outage of a source does not stop the quotes
+func NewFallbackProvider(providers ...QuoteProvider) QuoteProvider {
+	return &fallbackProvider{
+		providers: providers,
+	}
+}
+
+func (p *fallbackProvider) Name() string {
+	names := make([]string, 0, len(p.providers))
+	for _, provider := range p.providers {
+		names = append(names, provider.Name())
+	}
+
+	return strings.Join(names, ",")
+}
+
+// GetQuotes asks every provider for the codes not quoted yet, the codes no provider quoted keep the first error they got
+func (p *fallbackProvider) GetQuotes(codes []string) ([]*Quote, error) {
+	quotes := make([]*Quote, len(codes))
+	pending := make([]int, len(codes))
+	for i := range codes {
+		pending[i] = i
+	}
+
+	var lastErr error
+	for _, provider := range p.providers {
+		if len(pending) == 0 {
+			break
+		}
+
+		requested := make([]string, 0, len(pending))
+		for _, i := range pending {
+			requested = append(requested, codes[i])
+		}
+
+		got, err := provider.GetQuotes(requested)
+		if err != nil {
+			log.Printf("error getting quotes from %s: %s", provider.Name(), err)
+			lastErr = err
+			continue
+		}
+
+		var failed []int
+		for j, i := range pending {
+			if quotes[i] == nil || got[j].Err == nil {
+				quotes[i] = got[j]
+			}
+			if got[j].Err != nil {
+				failed = append(failed, i)
+			}
+		}
+		pending = failed
+	}
+
+	for _, q := range quotes {
+		if q == nil {
+			return nil, fmt.Errorf("error getting quotes from every provider: %w", lastErr)
+		}
+	}
+
+	return quotes, nil
+}
+
+// fail records the error getting the quote, and its description sent to the server
+func (q *Quote) fail(err error) *Quote {
+	q.Err = err
+	q.Error = err.Error()
+	return q
+}
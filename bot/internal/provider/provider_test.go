@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFallbackProviderSkipsFailingProviders(t *testing.T) {
+	down := NewFakeProvider(nil)
+	down.Err = errors.New("service unavailable")
+
+	up := NewFakeProvider(nil)
+
+	quotes, err := NewFallbackProvider(down, up).GetQuotes([]string{"aapl.us"})
+	if err != nil {
+		t.Fatalf("Expected the quotes of the second provider, got %s", err)
+	}
+
+	if quotes[0].Close != 178.85 {
+		t.Errorf("Expected the AAPL.US close to be 178.85, got %.2f", quotes[0].Close)
+	}
+}
+
+func TestFallbackProviderAsksOnlyTheFailedCodes(t *testing.T) {
+	primary := NewFakeProvider(map[string]*Quote{
+		"aapl.us": {Symbol: "AAPL.US", Close: 178.85},
+	})
+	secondary := NewFakeProvider(map[string]*Quote{
+		"msft.us": {Symbol: "MSFT.US", Close: 332.42},
+	})
+
+	quotes, err := NewFallbackProvider(primary, secondary).GetQuotes([]string{"aapl.us", "msft.us", "xyz.us"})
+	if err != nil {
+		t.Fatalf("Expected the quotes, got %s", err)
+	}
+
+	if len(secondary.Requests) != 1 || len(secondary.Requests[0]) != 2 {
+		t.Errorf("Expected the secondary provider to be asked only for the failed codes, got %v", secondary.Requests)
+	}
+
+	if quotes[0].Close != 178.85 || quotes[1].Close != 332.42 {
+		t.Errorf("Expected the quotes in the requested order, got %v and %v", quotes[0], quotes[1])
+	}
+
+	if !errors.Is(quotes[2].Err, ErrStockNotFound) {
+		t.Errorf("Expected XYZ.US not to be found, got %v", quotes[2].Err)
+	}
+}
+
+func TestFallbackProviderFailsWhenEveryProviderFails(t *testing.T) {
+	down := NewFakeProvider(nil)
+	down.Err = errors.New("service unavailable")
+
+	if _, err := NewFallbackProvider(down).GetQuotes([]string{"aapl.us"}); err == nil {
+		t.Error("Expected an error when no provider answers")
+	}
+}
+
+func TestYahooSymbol(t *testing.T) {
+	tests := map[string]string{
+		"aapl.us": "AAPL",
+		"vod.uk":  "VOD.L",
+		"sap.de":  "SAP.DE",
+		"btcusd":  "BTC-USD",
+		"dogeeur": "DOGE-EUR",
+		"xyzusd":  "XYZUSD",
+		"eurusd":  "EURUSD=X",
+		"usdjpy":  "USDJPY=X",
+	}
+
+	for code, want := range tests {
+		if got := yahooSymbol(code); got != want {
+			t.Errorf("yahooSymbol(%q) = %q, want %q", code, got, want)
+		}
+	}
+}
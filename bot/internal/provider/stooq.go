@@ -0,0 +1,188 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	stooqUrl        = "https://stooq.com/q/l/?s=%s&f=sd2t2ohlcv&h&e=csv"
+	stooqHistoryUrl = "https://stooq.com/q/d/l/?s=%s&d1=%s&d2=%s&i=d"
+	stooqSource     = "stooq"
+	stooqDateLayout = "20060102"
+)
+
+type stooqProvider struct {
+	url        string
+	historyUrl string
+	client     *httpClient
+}
+
+// NewStooqProvider builds a provider for the stooq CSV API
+func NewStooqProvider() QuoteProvider {
+	return newStooqProvider()
+}
+
+// NewStooqHistoryProvider builds a history provider for the stooq daily CSV API
+func NewStooqHistoryProvider() HistoryProvider {
+	return newStooqProvider()
+}
+
+func newStooqProvider() *stooqProvider {
+	return &stooqProvider{
+		url:        stooqUrl,
+		historyUrl: stooqHistoryUrl,
+		client:     newHTTPClient(),
+	}
+}
+
+func (p *stooqProvider) Name() string {
+	return stooqSource
+}
+
+// GetQuotes fetches the quotes of all the stock codes with a single request to the stooq API
+// and parses the returned CSV to extract the quote of every code, in the order they were requested
+func (p *stooqProvider) GetQuotes(codes []string) ([]*Quote, error) {
+	body, err := p.get(fmt.Sprintf(p.url, strings.Join(codes, "+")))
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(bytes.NewReader(body))
+
+	header, err := reader.Read()
+	if err != nil || len(header) < 8 {
+		return nil, fmt.Errorf("%w: unexpected stooq header %v", ErrMalformed, header)
+	}
+
+	// stooq answers with a row per symbol, identified by the symbol in upper case
+	rows := make(map[string][]string)
+	for {
+		records, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: error reading row: %s", ErrMalformed, err)
+		}
+
+		rows[strings.ToUpper(records[0])] = records
+	}
+
+	quotes := make([]*Quote, 0, len(codes))
+	for _, code := range codes {
+		quotes = append(quotes, parseStooqQuote(code, rows[strings.ToUpper(code)]))
+	}
+
+	return quotes, nil
+}
+
+// parseStooqQuote extracts the quote of a stock code from its CSV row: symbol, date, time, open, high, low, close, volume
+func parseStooqQuote(code string, records []string) *Quote {
+	quote := &Quote{
+		Symbol: strings.ToUpper(code),
+		Source: stooqSource,
+	}
+
+	if len(records) < 8 {
+		return quote.fail(fmt.Errorf("%w: missing quote row", ErrMalformed))
+	}
+
+	if records[6] == "N/D" {
+		return quote.fail(ErrStockNotFound)
+	}
+
+	quote.Date, quote.Time = records[1], records[2]
+
+	values := []*float64{&quote.Open, &quote.High, &quote.Low, &quote.Close}
+	for i, v := range values {
+		value, err := strconv.ParseFloat(records[3+i], 64)
+		if err != nil {
+			return quote.fail(fmt.Errorf("%w: error parsing quote value: %s", ErrMalformed, err))
+		}
+		*v = value
+	}
+
+	// the volume is not available for every symbol, such as indices
+	if volume, err := strconv.ParseFloat(records[7], 64); err == nil {
+		quote.Volume = int64(volume)
+	}
+
+	return quote
+}
+
+// GetHistory fetches the daily CSV of a stock code: date, open, high, low, close, volume
+func (p *stooqProvider) GetHistory(code string, from time.Time, to time.Time) ([]*Bar, error) {
+	body, err := p.get(fmt.Sprintf(p.historyUrl, code, from.Format(stooqDateLayout), to.Format(stooqDateLayout)))
+	if err != nil {
+		return nil, err
+	}
+
+	// stooq answers the unknown codes, and the ranges without sessions, with a plain text message
+	if bytes.HasPrefix(body, []byte("No data")) {
+		return nil, ErrStockNotFound
+	}
+
+	reader := csv.NewReader(bytes.NewReader(body))
+
+	header, err := reader.Read()
+	if err != nil || len(header) < 5 {
+		return nil, fmt.Errorf("%w: unexpected stooq header %v", ErrMalformed, header)
+	}
+
+	var bars []*Bar
+	for {
+		records, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil || len(records) < 5 {
+			return nil, fmt.Errorf("%w: error reading row: %v", ErrMalformed, err)
+		}
+
+		bar := &Bar{Date: records[0]}
+
+		values := []*float64{&bar.Open, &bar.High, &bar.Low, &bar.Close}
+		for i, v := range values {
+			value, err := strconv.ParseFloat(records[1+i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: error parsing history value: %s", ErrMalformed, err)
+			}
+			*v = value
+		}
+
+		// the volume is not available for every symbol, such as indices
+		if len(records) > 5 {
+			if volume, err := strconv.ParseFloat(records[5], 64); err == nil {
+				bar.Volume = int64(volume)
+			}
+		}
+
+		bars = append(bars, bar)
+	}
+
+	return bars, nil
+}
+
+// get requests stooq, which answers with a 200 and a plain text message when the daily hits limit is exceeded
+func (p *stooqProvider) get(url string) ([]byte, error) {
+	status, body, err := p.client.get(url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("%w: stooq answered with status %d", ErrUnavailable, status)
+	}
+
+	if bytes.HasPrefix(body, []byte("Exceeded")) {
+		return nil, fmt.Errorf("%w: %s", ErrRateLimited, bytes.TrimSpace(body))
+	}
+
+	return body, nil
+}
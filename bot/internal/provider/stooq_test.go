@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const stooqCSV = `Symbol,Date,Time,Open,High,Low,Close,Volume
+AAPL.US,2023-10-16,22:00:07,176.75,179.075,176.51,178.72,52517010
+XYZ.US,N/D,N/D,N/D,N/D,N/D,N/D,N/D
+`
+
+// newTestStooqProvider builds a stooq provider requesting the test server, without waiting between retries
+func newTestStooqProvider(server *httptest.Server) *stooqProvider {
+	client := newHTTPClient()
+	client.sleep = func(time.Duration) {}
+
+	return &stooqProvider{
+		url:    server.URL + "/?s=%s",
+		client: client,
+	}
+}
+
+func TestStooqProviderRetriesServerErrors(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(stooqCSV))
+	}))
+	defer server.Close()
+
+	quotes, err := newTestStooqProvider(server).GetQuotes([]string{"aapl.us", "xyz.us"})
+	if err != nil {
+		t.Fatalf("Expected the quotes after retrying, got %s", err)
+	}
+
+	if quotes[0].Close != 178.72 || quotes[0].Volume != 52517010 {
+		t.Errorf("Expected the AAPL.US quote, got %+v", quotes[0])
+	}
+
+	if !errors.Is(quotes[1].Err, ErrStockNotFound) {
+		t.Errorf("Expected XYZ.US not to be found, got %v", quotes[1].Err)
+	}
+}
+
+func TestStooqProviderClassifiesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr error
+	}{
+		{name: "unavailable", status: http.StatusServiceUnavailable, wantErr: ErrUnavailable},
+		{name: "rate limited status", status: http.StatusTooManyRequests, wantErr: ErrRateLimited},
+		{name: "daily hits limit", status: http.StatusOK, body: "Exceeded the daily hits limit", wantErr: ErrRateLimited},
+		{name: "malformed", status: http.StatusOK, body: "<html>maintenance</html>", wantErr: ErrMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := newTestStooqProvider(server).GetQuotes([]string{"aapl.us"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	yahooUrl    = "https://query1.finance.yahoo.com/v8/finance/chart/%s?range=1d&interval=1d"
+	yahooSource = "yahoo"
+)
+
+// yahooSuffixes maps the market suffix of the stooq codes to the yahoo ones
+var yahooSuffixes = map[string]string{
+	"us": "",
+	"uk": ".L",
+	"de": ".DE",
+	"jp": ".T",
+	"hk": ".HK",
+	"pl": ".WA",
+}
+
+// yahooCryptos are the cryptocurrencies quoted by yahoo as <crypto>-<currency>, such as BTC-USD
+var yahooCryptos = map[string]bool{
+	"btc":  true,
+	"eth":  true,
+	"sol":  true,
+	"xrp":  true,
+	"ada":  true,
+	"doge": true,
+	"ltc":  true,
+	"dot":  true,
+	"bnb":  true,
+}
+
+// yahooChart is the subset of the yahoo chart API response the quotes are read from
+type yahooChart struct {
+	Chart struct {
+		Result []struct {
+			Meta struct {
+				RegularMarketPrice float64 `json:"regularMarketPrice"`
+				RegularMarketTime  int64   `json:"regularMarketTime"`
+			} `json:"meta"`
+			Indicators struct {
+				Quote []struct {
+					Open   []*float64 `json:"open"`
+					High   []*float64 `json:"high"`
+					Low    []*float64 `json:"low"`
+					Close  []*float64 `json:"close"`
+					Volume []*int64   `json:"volume"`
+				} `json:"quote"`
+			} `json:"indicators"`
+		} `json:"result"`
+		Error *struct {
+			Code        string `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	} `json:"chart"`
+}
+
+type yahooProvider struct {
+	url    string
+	client *httpClient
+}
+
+// NewYahooProvider builds a provider for the yahoo finance chart JSON API
+func NewYahooProvider() QuoteProvider {
+	return &yahooProvider{
+		url:    yahooUrl,
+		client: newHTTPClient(),
+	}
+}
+
+func (p *yahooProvider) Name() string {
+	return yahooSource
+}
+
+// GetQuotes fetches the daily chart of every stock code, the API only accepts one symbol per request
+func (p *yahooProvider) GetQuotes(codes []string) ([]*Quote, error) {
+	quotes := make([]*Quote, 0, len(codes))
+
+	for _, code := range codes {
+		quote, err := p.getQuote(code)
+		if err != nil {
+			return nil, err
+		}
+		quotes = append(quotes, quote)
+	}
+
+	return quotes, nil
+}
+
+// getQuote fetches the daily chart of a stock code, it only returns an error if the API could not be reached or is rate limited
+func (p *yahooProvider) getQuote(code string) (*Quote, error) {
+	quote := &Quote{
+		Symbol: strings.ToUpper(code),
+		Source: yahooSource,
+	}
+
+	// yahoo rejects the requests without a browser-like user agent
+	header := http.Header{"User-Agent": []string{"Mozilla/5.0"}}
+
+	status, body, err := p.client.get(fmt.Sprintf(p.url, yahooSymbol(code)), header)
+	if err != nil {
+		return nil, err
+	}
+
+	// unknown symbols are answered with a 404 and a chart error
+	var chart yahooChart
+	if err := json.Unmarshal(body, &chart); err != nil {
+		if status != http.StatusOK {
+			return nil, fmt.Errorf("%w: yahoo answered with status %d", ErrUnavailable, status)
+		}
+		return quote.fail(fmt.Errorf("%w: error decoding yahoo chart: %s", ErrMalformed, err)), nil
+	}
+
+	if chart.Chart.Error != nil {
+		if chart.Chart.Error.Code == "Not Found" {
+			return quote.fail(ErrStockNotFound), nil
+		}
+		return quote.fail(fmt.Errorf("%w: yahoo error: %s", ErrUnavailable, chart.Chart.Error.Description)), nil
+	}
+
+	if len(chart.Chart.Result) == 0 || len(chart.Chart.Result[0].Indicators.Quote) == 0 {
+		return quote.fail(ErrStockNotFound), nil
+	}
+
+	result := chart.Chart.Result[0]
+	ohlcv := result.Indicators.Quote[0]
+
+	ts := time.Unix(result.Meta.RegularMarketTime, 0).UTC()
+	quote.Date, quote.Time = ts.Format("2006-01-02"), ts.Format("15:04:05")
+
+	quote.Open, quote.High, quote.Low = last(ohlcv.Open), last(ohlcv.High), last(ohlcv.Low)
+	quote.Close = result.Meta.RegularMarketPrice
+	if len(ohlcv.Volume) > 0 && ohlcv.Volume[len(ohlcv.Volume)-1] != nil {
+		quote.Volume = *ohlcv.Volume[len(ohlcv.Volume)-1]
+	}
+
+	return quote, nil
+}
+
+// yahooSymbol translates a stooq code such as aapl.us or vod.uk to its yahoo symbol, AAPL or VOD.L
+// a currency pair such as eurusd to its yahoo symbol, EURUSD=X, and a crypto pair such as btcusd to BTC-USD
+func yahooSymbol(code string) string {
+	name, market, found := strings.Cut(strings.ToLower(code), ".")
+	if !found {
+		if isCurrencyPair(name) {
+			return strings.ToUpper(name) + "=X"
+		}
+		if crypto, currency, ok := cryptoPair(name); ok {
+			return strings.ToUpper(crypto + "-" + currency)
+		}
+		return strings.ToUpper(code)
+	}
+
+	suffix, ok := yahooSuffixes[market]
+	if !ok {
+		suffix = "." + market
+	}
+
+	return strings.ToUpper(name + suffix)
+}
+
+// isCurrencyPair reports whether a code is a pair of known currencies, such as eurusd
+func isCurrencyPair(code string) bool {
+	if len(code) != 6 {
+		return false
+	}
+
+	_, from := LookupCurrency(code[:3])
+	_, to := LookupCurrency(code[3:])
+	return from && to
+}
+
+// cryptoPair splits a pair of a known cryptocurrency and a known currency, such as btcusd
+func cryptoPair(code string) (string, string, bool) {
+	if len(code) <= 3 {
+		return "", "", false
+	}
+
+	crypto, currency := code[:len(code)-3], code[len(code)-3:]
+	if _, ok := LookupCurrency(currency); !ok || !yahooCryptos[crypto] {
+		return "", "", false
+	}
+
+	return crypto, currency, true
+}
+
+// last returns the last value of a chart series, or 0 if it has none
+func last(values []*float64) float64 {
+	if len(values) == 0 || values[len(values)-1] == nil {
+		return 0
+	}
+
+	return *values[len(values)-1]
+}
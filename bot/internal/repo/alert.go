@@ -0,0 +1,95 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Alert is an active alert created by a user of the chat, the server owns the alerts table
+type Alert struct {
+	ID        int64   `json:"id"`
+	UserID    string  `json:"userID"`
+	RoomID    string  `json:"roomID"`
+	Symbol    string  `json:"symbol"`
+	Operator  string  `json:"operator"`
+	Threshold float64 `json:"threshold"`
+	Username  string  `json:"-"`
+}
+
+type AlertRepo interface {
+	GetActiveAlerts(ctx context.Context) ([]*Alert, error)
+	MarkTriggered(ctx context.Context, id int64) (bool, error)
+	ClearTriggered(ctx context.Context, id int64) error
+}
+
+type alertRepository struct {
+	db *sql.DB
+}
+
+// NewAlertRepository builds an alertRepository and injects its dependencies
+func NewAlertRepository(db *sql.DB) AlertRepo {
+	return &alertRepository{db: db}
+}
+
+// GetActiveAlerts returns the alerts that have not triggered yet, with the username of their owner
+func (r *alertRepository) GetActiveAlerts(ctx context.Context) ([]*Alert, error) {
+	query := `
+		SELECT alerts.id, alerts.user_id, alerts.room_id, alerts.symbol, alerts.operator, alerts.threshold, users.username
+		FROM alerts
+		INNER JOIN users ON alerts.user_id = users.id
+		WHERE alerts.triggered_at IS NULL
+		ORDER BY alerts.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("error querying the alerts table: %s", err))
+	}
+	defer rows.Close()
+
+	var alerts []*Alert
+	for rows.Next() {
+		a := &Alert{}
+		if err := rows.Scan(&a.ID, &a.UserID, &a.RoomID, &a.Symbol, &a.Operator, &a.Threshold, &a.Username); err != nil {
+			return nil, errors.New(fmt.Sprintf("error scanning rows: %s", err))
+		}
+		alerts = append(alerts, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.New(fmt.Sprintf("error during rows iteration: %s", err))
+	}
+
+	return alerts, nil
+}
+
+// MarkTriggered marks an alert as triggered, returning false if it was removed or had already triggered
+// so every alert is notified at most once, even with several bots checking them
+func (r *alertRepository) MarkTriggered(ctx context.Context, id int64) (bool, error) {
+	query := `UPDATE alerts SET triggered_at = now() WHERE id = $1 AND triggered_at IS NULL`
+
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, errors.New(fmt.Sprintf("error updating alert: %s", err))
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, errors.New(fmt.Sprintf("error updating alert: %s", err))
+	}
+
+	return n > 0, nil
+}
+
+// ClearTriggered makes a triggered alert active again, when its notification could not be published
+func (r *alertRepository) ClearTriggered(ctx context.Context, id int64) error {
+	query := `UPDATE alerts SET triggered_at = NULL WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return errors.New(fmt.Sprintf("error updating alert: %s", err))
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"time"
+)
+
+// DigestSchedule posts a digest of the symbols to the room at the times of its cron expression, in its time zone
+type DigestSchedule struct {
+	ID       int64
+	RoomID   string
+	Name     string
+	Cron     string
+	Timezone string
+	Symbols  []string
+}
+
+type DigestRepo interface {
+	GetSchedules(ctx context.Context) ([]*DigestSchedule, error)
+	MarkRun(ctx context.Context, id int64, at time.Time) (bool, error)
+	ClearRun(ctx context.Context, id int64, at time.Time) error
+}
+
+type digestRepository struct {
+	db *sql.DB
+}
+
+// NewDigestRepository builds a digestRepository and injects its dependencies
+func NewDigestRepository(db *sql.DB) DigestRepo {
+	return &digestRepository{db: db}
+}
+
+// GetSchedules returns the enabled digest schedules
+func (r *digestRepository) GetSchedules(ctx context.Context) ([]*DigestSchedule, error) {
+	query := `SELECT id, room_id, name, cron, timezone, symbols FROM digest_schedules WHERE enabled ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("error querying the digest_schedules table: %s", err))
+	}
+	defer rows.Close()
+
+	var schedules []*DigestSchedule
+	for rows.Next() {
+		d := &DigestSchedule{}
+		if err := rows.Scan(&d.ID, &d.RoomID, &d.Name, &d.Cron, &d.Timezone, pq.Array(&d.Symbols)); err != nil {
+			return nil, errors.New(fmt.Sprintf("error scanning rows: %s", err))
+		}
+		schedules = append(schedules, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.New(fmt.Sprintf("error during rows iteration: %s", err))
+	}
+
+	return schedules, nil
+}
+
+// MarkRun records the run of a schedule at the given minute, returning false if it already ran at it
+// so every digest is posted once, even with several bots running the schedules
+func (r *digestRepository) MarkRun(ctx context.Context, id int64, at time.Time) (bool, error) {
+	query := `UPDATE digest_schedules SET last_run_at = $2 WHERE id = $1 AND (last_run_at IS NULL OR last_run_at < $2)`
+
+	res, err := r.db.ExecContext(ctx, query, id, at.UTC())
+	if err != nil {
+		return false, errors.New(fmt.Sprintf("error updating digest schedule: %s", err))
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, errors.New(fmt.Sprintf("error updating digest schedule: %s", err))
+	}
+
+	return n > 0, nil
+}
+
+// ClearRun undoes the run of a schedule at the given minute, when its digest could not be published
+// so the minute can be run again, while the earlier ones stay run
+func (r *digestRepository) ClearRun(ctx context.Context, id int64, at time.Time) error {
+	query := `UPDATE digest_schedules SET last_run_at = $3 WHERE id = $1 AND last_run_at = $2`
+
+	if _, err := r.db.ExecContext(ctx, query, id, at.UTC(), at.Add(-time.Minute).UTC()); err != nil {
+		return errors.New(fmt.Sprintf("error updating digest schedule: %s", err))
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"bot/internal/provider"
+	"bot/internal/repo"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/streadway/amqp"
+	"infra"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	alertIntervalEnv     = "ALERT_INTERVAL"
+	defaultAlertInterval = time.Minute
+)
+
+// AlertService checks the active alerts periodically and notifies the ones whose threshold was crossed
+type AlertService struct {
+	Manager  *infra.ConnectionManager
+	Provider provider.QuoteProvider
+	Repo     repo.AlertRepo
+	Interval time.Duration
+
+	// publish sends a triggered alert to the server, it is replaced in the tests
+	publish func(body []byte) error
+}
+
+// alertPayload is the notification of a triggered alert, the server posts the message mentioning the username
+type alertPayload struct {
+	Alert    *repo.Alert     `json:"alert"`
+	Username string          `json:"username"`
+	Message  string          `json:"message"`
+	Quote    *provider.Quote `json:"quote,omitempty"`
+}
+
+// AlertIntervalFromEnv reads the interval between the checks of the alerts from the ALERT_INTERVAL env variable, such as 1m
+func AlertIntervalFromEnv() (time.Duration, error) {
+	value := os.Getenv(alertIntervalEnv)
+	if value == "" {
+		return defaultAlertInterval, nil
+	}
+
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		return 0, errors.New(fmt.Sprintf("invalid %s: %q", alertIntervalEnv, value))
+	}
+
+	return interval, nil
+}
+
+// NewAlertService builds a service and injects its dependencies
+func NewAlertService(m *infra.ConnectionManager, p provider.QuoteProvider, r repo.AlertRepo, interval time.Duration) *AlertService {
+	s := &AlertService{
+		Manager:  m,
+		Provider: p,
+		Repo:     r,
+		Interval: interval,
+	}
+
+	s.publish = func(body []byte) error {
+		return s.Manager.Publish(exchangeName, alertKey, amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			Body:         body,
+		})
+	}
+
+	return s
+}
+
+// Run checks the alerts every interval, it never returns
+func (s *AlertService) Run() {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := s.check(context.Background()); err != nil {
+			log.Printf("error checking alerts: %s", err)
+		}
+	}
+}
+
+// check fetches the quotes of every symbol with active alerts at once, and notifies the alerts that crossed their threshold
+func (s *AlertService) check(ctx context.Context) error {
+	alerts, err := s.Repo.GetActiveAlerts(ctx)
+	if err != nil {
+		return err
+	}
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	var symbols []string
+	seen := make(map[string]bool)
+	for _, a := range alerts {
+		if !seen[a.Symbol] {
+			seen[a.Symbol] = true
+			symbols = append(symbols, a.Symbol)
+		}
+	}
+
+	quotes, err := s.Provider.GetQuotes(symbols)
+	if err != nil {
+		return errors.New(fmt.Sprintf("error getting quotes from %s: %s", s.Provider.Name(), err))
+	}
+
+	bySymbol := make(map[string]*provider.Quote)
+	for i, q := range quotes {
+		if q.Err != nil {
+			log.Printf("error getting the quote of %s for alerts: %s", q.Symbol, q.Err)
+			continue
+		}
+		bySymbol[symbols[i]] = q
+	}
+
+	for _, a := range alerts {
+		q, ok := bySymbol[a.Symbol]
+		if !ok || !crossed(a, q.Close) {
+			continue
+		}
+
+		if err := s.notify(ctx, a, q); err != nil {
+			log.Printf("error notifying alert %d: %s", a.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// notify marks the alert as triggered and publishes its notification, unless another check already triggered it
+// an alert whose notification cannot be published is active again, so the next check notifies it
+func (s *AlertService) notify(ctx context.Context, a *repo.Alert, q *provider.Quote) error {
+	direction := "above"
+	if a.Operator == "<" {
+		direction = "below"
+	}
+
+	c := provider.CurrencyOf(a.Symbol)
+
+	body, err := json.Marshal(alertPayload{
+		Alert:    a,
+		Username: a.Username,
+		Message:  fmt.Sprintf("%s crossed %s %s, it is %s now", strings.ToUpper(a.Symbol), direction, c.Format(a.Threshold), c.Format(q.Close)),
+		Quote:    q,
+	})
+	if err != nil {
+		return errors.New(fmt.Sprintf("error marshaling payload: %s", err))
+	}
+
+	triggered, err := s.Repo.MarkTriggered(ctx, a.ID)
+	if err != nil || !triggered {
+		return err
+	}
+
+	if err := s.publish(body); err != nil {
+		if err := s.Repo.ClearTriggered(ctx, a.ID); err != nil {
+			log.Printf("error clearing the trigger of alert %d: %s", a.ID, err)
+		}
+		return errors.New(fmt.Sprintf("error publishing to the exchange: %s", err))
+	}
+
+	log.Printf("Alert sent: %s\n", string(body))
+	return nil
+}
+
+// crossed reports whether the price is beyond the threshold of the alert
+func crossed(a *repo.Alert, price float64) bool {
+	switch a.Operator {
+	case ">":
+		return price > a.Threshold
+	case "<":
+		return price < a.Threshold
+	default:
+		return false
+	}
+}
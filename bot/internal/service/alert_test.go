@@ -0,0 +1,130 @@
+package service
+
+import (
+	"bot/internal/provider"
+	"bot/internal/repo"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// fakeAlertRepo keeps the alerts in memory
+type fakeAlertRepo struct {
+	alerts    []*repo.Alert
+	triggered map[int64]bool
+}
+
+func (r *fakeAlertRepo) GetActiveAlerts(ctx context.Context) ([]*repo.Alert, error) {
+	var active []*repo.Alert
+	for _, a := range r.alerts {
+		if !r.triggered[a.ID] {
+			active = append(active, a)
+		}
+	}
+	return active, nil
+}
+
+func (r *fakeAlertRepo) MarkTriggered(ctx context.Context, id int64) (bool, error) {
+	if r.triggered[id] {
+		return false, nil
+	}
+	r.triggered[id] = true
+	return true, nil
+}
+
+func (r *fakeAlertRepo) ClearTriggered(ctx context.Context, id int64) error {
+	delete(r.triggered, id)
+	return nil
+}
+
+func TestCheckAlerts(t *testing.T) {
+	alerts := &fakeAlertRepo{
+		alerts: []*repo.Alert{
+			{ID: 1, Symbol: "aapl.us", Operator: ">", Threshold: 170, Username: "alice"},
+			{ID: 2, Symbol: "aapl.us", Operator: "<", Threshold: 170, Username: "bob"},
+			{ID: 3, Symbol: "msft.us", Operator: "<", Threshold: 400, Username: "bob"},
+		},
+		triggered: make(map[int64]bool),
+	}
+
+	p := provider.NewFakeProvider(map[string]*provider.Quote{
+		"aapl.us": {Symbol: "AAPL.US", Close: 178.85},
+		"msft.us": {Symbol: "MSFT.US", Close: 330.5},
+	})
+
+	var published []alertPayload
+	s := NewAlertService(nil, p, alerts, defaultAlertInterval)
+	s.publish = func(body []byte) error {
+		var pl alertPayload
+		if err := json.Unmarshal(body, &pl); err != nil {
+			t.Fatalf("failed to unmarshal the alert: %s", err)
+		}
+		published = append(published, pl)
+		return nil
+	}
+
+	if err := s.check(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(p.Requests) != 1 || len(p.Requests[0]) != 2 {
+		t.Errorf("expected a single request of both symbols, got %v", p.Requests)
+	}
+
+	if len(published) != 2 {
+		t.Fatalf("expected 2 alerts, got %d", len(published))
+	}
+	if got, want := published[0].Message, "AAPL.US crossed above $170.00, it is $178.85 now"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := published[1].Username; got != "bob" {
+		t.Errorf("expected the alert of bob, got %q", got)
+	}
+
+	// triggered alerts are not notified again
+	published = nil
+	if err := s.check(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(published) != 0 {
+		t.Errorf("expected no alerts, got %d", len(published))
+	}
+}
+
+func TestCheckAlertsRetriesFailedNotifications(t *testing.T) {
+	alerts := &fakeAlertRepo{
+		alerts:    []*repo.Alert{{ID: 1, Symbol: "aapl.us", Operator: ">", Threshold: 170, Username: "alice"}},
+		triggered: make(map[int64]bool),
+	}
+
+	p := provider.NewFakeProvider(map[string]*provider.Quote{
+		"aapl.us": {Symbol: "AAPL.US", Close: 178.85},
+	})
+
+	published := 0
+	failing := true
+	s := NewAlertService(nil, p, alerts, defaultAlertInterval)
+	s.publish = func(body []byte) error {
+		if failing {
+			return errors.New("channel closed")
+		}
+		published++
+		return nil
+	}
+
+	if err := s.check(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if alerts.triggered[1] {
+		t.Error("expected the alert to stay active when its notification fails")
+	}
+
+	failing = false
+	if err := s.check(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if published != 1 || !alerts.triggered[1] {
+		t.Errorf("expected the alert to be notified by the next check, got %d notifications", published)
+	}
+}
@@ -4,69 +4,168 @@ import (
 	"errors"
 	"fmt"
 	"github.com/streadway/amqp"
+	"infra"
 	"log"
-	"os"
+	"strconv"
+	"time"
 )
 
 const (
 	exchangeName = "stockchat"
-	queueName    = "stockchat-queue-stocks"
-	stockKey     = "messages.stock"
-	quoteKey     = "messages.quote"
+	// the requests queue is durable and dead-letters the rejected requests, it replaces the transient legacy queue
+	// whose arguments cannot be changed on a running broker
+	queueName       = "stockchat-queue-requests"
+	legacyQueueName = "stockchat-queue-stocks"
+
+	// the requests that fail transiently wait in the retry queue until their ttl expires, and are routed back to the exchange
+	retryExchangeName = "stockchat-retry"
+	retryQueueName    = "stockchat-queue-stocks-retry"
+
+	// the poison requests are dead-lettered to the dlq, to be inspected and replayed with the dlq command
+	deadLetterExchangeName = "stockchat-dlx"
+	deadLetterQueueName    = "stockchat-queue-stocks-dead"
+
+	attemptsHeader = "x-attempts"
+	replayedHeader = "x-replayed"
+	stockKey       = "messages.stock"
+	historyKey     = "messages.history"
+	chartKey       = "messages.chart"
+	searchKey      = "messages.search"
+	fxKey          = "messages.fx"
+	cryptoKey      = "messages.crypto"
+	quoteKey       = "messages.quote"
+	alertKey       = "messages.alert"
+	digestKey      = "messages.quote.digest"
 )
 
-// setupAMQExchange configures and returns a connection and exchange to rabbitmq
-func setupAMQExchange() (*amqp.Connection, *amqp.Channel, error) {
-	user, password, host := os.Getenv("RABBITMQ_USERNAME"), os.Getenv("RABBITMQ_PASSWORD"), os.Getenv("RABBITMQ_HOST")
+// setupAMQExchange connects to rabbitmq and declares the exchange <stockchat>, along with the retry queue and the dlq
+// the connection and exchanges are restored automatically if rabbitmq restarts
+func setupAMQExchange(m *infra.ConnectionManager) error {
+	if err := m.Connect(); err != nil {
+		return err
+	}
+
+	return m.Declare(func(ch infra.Channel) error {
+		for _, name := range []string{exchangeName, retryExchangeName, deadLetterExchangeName} {
+			if err := ch.ExchangeDeclare(name, "topic", true, false, false, false, nil); err != nil {
+				return errors.New(fmt.Sprintf("error declaring amqp exchange: %s", err))
+			}
+		}
 
-	amqpUrl := fmt.Sprintf("amqp://%s:%s@%s/", user, password, host)
+		// the expired retries are dead-lettered back to the exchange with their original routing key
+		retryArgs := amqp.Table{"x-dead-letter-exchange": exchangeName}
+		if err := declareBoundQueue(ch, retryQueueName, retryExchangeName, retryArgs); err != nil {
+			return err
+		}
 
-	conn, err := amqp.Dial(amqpUrl)
+		return declareBoundQueue(ch, deadLetterQueueName, deadLetterExchangeName, nil)
+	})
+}
+
+// removeLegacyQueue deletes the queue the requests were consumed from before the requests queue, so it stops receiving
+// a copy of every request once the requests queue is consumed, the requests left in it are dropped as a restart would
+// it is kept while an older bot still consumes it, and deleted by the next bot that starts
+func removeLegacyQueue(m *infra.ConnectionManager) {
+	err := m.WithChannel(func(ch infra.Channel) error {
+		_, err := ch.QueueDelete(legacyQueueName, true, false, false)
+		return err
+	})
 	if err != nil {
-		return nil, nil, errors.New(fmt.Sprintf("error dialing amqp: %s", err))
+		log.Printf("error deleting the legacy queue %s: %s", legacyQueueName, err)
 	}
+}
 
-	ch, err := conn.Channel()
+// declareBoundQueue declares a durable queue receiving every message of the exchange
+func declareBoundQueue(ch infra.Channel, name string, exchange string, args amqp.Table) error {
+	q, err := ch.QueueDeclare(name, true, false, false, false, args)
 	if err != nil {
-		return nil, nil, errors.New(fmt.Sprintf("error opening amqp channel: %s", err))
+		return errors.New(fmt.Sprintf("error declaring queue: %s", err))
 	}
 
-	if err = ch.ExchangeDeclare(exchangeName, "topic", true, false, false, false, nil); err != nil {
-		log.Printf("error declaring exchange: %s", err)
-		return nil, nil, errors.New(fmt.Sprintf("error declaring amqp exchange: %s", err))
+	if err := ch.QueueBind(q.Name, "#", exchange, false, nil); err != nil {
+		return errors.New(fmt.Sprintf("error binding exchange to queue: %s", err))
 	}
 
-	return conn, ch, nil
+	return nil
 }
 
 // publishAMQMessage publishes a message to the amq exchange
-func publishAMQMessage(ch *amqp.Channel, message []byte) error {
-	msg := amqp.Publishing{
-		ContentType: "text/plain",
-		Body:        message,
+// replies are routed with the ReplyTo key of the request, so they reach the server instance that sent it
+func publishAMQMessage(m *infra.ConnectionManager, replyTo string, correlationID string, message []byte) error {
+	routingKey := replyTo
+	if routingKey == "" {
+		routingKey = quoteKey
 	}
-	if err := ch.Publish(exchangeName, quoteKey, false, false, msg); err != nil {
-		return err
+
+	msg := amqp.Publishing{
+		ContentType:   "text/plain",
+		CorrelationId: correlationID,
+		Body:          message,
 	}
 
-	return nil
+	return m.Publish(exchangeName, routingKey, msg)
 }
 
-// consumeAMQMessages returns the messages from the subscribed queue
-func consumeAMQMessages(ch *amqp.Channel) (<-chan amqp.Delivery, error) {
-	q, err := ch.QueueDeclare(queueName, false, false, false, false, nil)
-	if err != nil {
-		return nil, errors.New(fmt.Sprintf("error declaring queue: %s", err))
+// publishRetry publishes a request to the retry queue, where it waits for delay before being routed back with its original key
+func publishRetry(m *infra.ConnectionManager, message amqp.Delivery, attempt int, delay time.Duration) error {
+	return m.Publish(retryExchangeName, message.RoutingKey, retryOf(message, attempt, delay))
+}
+
+// retryOf builds the retry of a request, it keeps the headers of the request, such as x-replayed, and counts the attempt
+func retryOf(message amqp.Delivery, attempt int, delay time.Duration) amqp.Publishing {
+	headers := amqp.Table{}
+	for k, v := range message.Headers {
+		headers[k] = v
 	}
+	headers[attemptsHeader] = int32(attempt)
 
-	if err = ch.QueueBind(q.Name, stockKey, exchangeName, false, nil); err != nil {
-		return nil, errors.New(fmt.Sprintf("error binding exchange to queue: %s", err))
+	return amqp.Publishing{
+		ContentType:   message.ContentType,
+		CorrelationId: message.CorrelationId,
+		ReplyTo:       message.ReplyTo,
+		DeliveryMode:  amqp.Persistent,
+		Expiration:    strconv.FormatInt(delay.Milliseconds(), 10),
+		Headers:       headers,
+		Body:          message.Body,
 	}
+}
 
-	messages, err := ch.Consume(q.Name, "", true, false, false, false, nil)
-	if err != nil {
-		return nil, errors.New(fmt.Sprintf("error consuming queued messages: %s", err))
+// attemptOf returns the attempt of a request, the first delivery is the attempt 1
+func attemptOf(message amqp.Delivery) int {
+	switch n := message.Headers[attemptsHeader].(type) {
+	case int32:
+		return int(n)
+	case int64:
+		return int(n)
+	case int:
+		return n
+	default:
+		return 1
 	}
+}
+
+// consumeAMQMessages returns the stock, history, chart, search, fx and crypto requests from the subscribed queue, consuming resumes after a reconnection
+// the requests must be acked, at most prefetch of them are delivered before they are, and consuming stops when done is closed
+// the queue is durable, and the rejected requests are dead-lettered to the dlq
+func consumeAMQMessages(m *infra.ConnectionManager, prefetch int, done <-chan struct{}) (<-chan amqp.Delivery, error) {
+	return m.Consume(infra.ConsumeOptions{
+		Queue:    queueName,
+		Prefetch: prefetch,
+		Done:     done,
+		Setup: func(ch infra.Channel) error {
+			args := amqp.Table{"x-dead-letter-exchange": deadLetterExchangeName}
+			q, err := ch.QueueDeclare(queueName, true, false, false, false, args)
+			if err != nil {
+				return errors.New(fmt.Sprintf("error declaring queue: %s", err))
+			}
+
+			for _, key := range []string{stockKey, historyKey, chartKey, searchKey, fxKey, cryptoKey} {
+				if err = ch.QueueBind(q.Name, key, exchangeName, false, nil); err != nil {
+					return errors.New(fmt.Sprintf("error binding exchange to queue: %s", err))
+				}
+			}
 
-	return messages, nil
+			return nil
+		},
+	})
 }
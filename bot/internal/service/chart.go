@@ -0,0 +1,102 @@
+package service
+
+import (
+	"bot/internal/provider"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+const defaultChartRange = "30d"
+
+// sparkBlocks are the Unicode blocks of a sparkline, from the lowest to the highest value
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// getChart fetches the last closes of the requested code and renders them as a sparkline reply
+// the provider failure, if any, is returned along with the reply
+func (s *StockService) getChart(spl *stockPayload) (string, error) {
+	if len(spl.StockCodes) == 0 {
+		return "Missing the stock code of the chart request", nil
+	}
+	code := spl.StockCodes[0]
+	symbol := strings.ToUpper(code)
+
+	if spl.Range == "" {
+		spl.Range = defaultChartRange
+	}
+
+	rng, err := parseHistoryRange(spl.Range)
+	if err != nil {
+		return fmt.Sprintf("%s. It should be something like /chart=aapl.us,30", err), nil
+	}
+
+	end := time.Now().UTC()
+
+	bars, err := s.History.GetHistory(code, rng.start(end), end)
+	if err != nil {
+		log.Printf("error getting the history of %s: %s", symbol, err)
+		return explainError(symbol, err), err
+	}
+
+	bars = rng.trim(bars)
+	if len(bars) == 0 {
+		return fmt.Sprintf("%s has no sessions in the last %s", symbol, rng.original), nil
+	}
+
+	return renderChart(symbol, bars), nil
+}
+
+// renderChart renders the closes of the bars, oldest first, as a sparkline with the first and last closes and the range
+func renderChart(symbol string, bars []*provider.Bar) string {
+	closes := make([]float64, 0, len(bars))
+	for _, bar := range bars {
+		closes = append(closes, bar.Close)
+	}
+
+	low, high := bounds(closes)
+
+	first, last := closes[0], closes[len(closes)-1]
+	change := 0.0
+	if first != 0 {
+		change = (last - first) / first * 100
+	}
+
+	c := provider.CurrencyOf(symbol)
+
+	return fmt.Sprintf(
+		"%s last %d closes %s %s to %s (%+.2f%%), low %s, high %s",
+		symbol, len(closes), sparkline(closes), c.Format(first), c.Format(last), change, c.Format(low), c.Format(high),
+	)
+}
+
+// sparkline maps every value to a block proportional to its position between the lowest and the highest value
+func sparkline(values []float64) string {
+	low, high := bounds(values)
+
+	var sb strings.Builder
+	for _, v := range values {
+		i := len(sparkBlocks) / 2
+		if high > low {
+			i = int((v - low) / (high - low) * float64(len(sparkBlocks)-1))
+		}
+		sb.WriteRune(sparkBlocks[i])
+	}
+
+	return sb.String()
+}
+
+// bounds returns the lowest and the highest value
+func bounds(values []float64) (float64, float64) {
+	low, high := values[0], values[0]
+	for _, v := range values {
+		if v < low {
+			low = v
+		}
+		if v > high {
+			high = v
+		}
+	}
+
+	return low, high
+}
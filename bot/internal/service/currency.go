@@ -0,0 +1,97 @@
+package service
+
+import (
+	"bot/internal/provider"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// splitTargets splits the codes with a target currency, such as sap.de:usd, into the codes and their target currencies
+// the codes without a target have an empty one
+func splitTargets(codes []string) ([]string, []string) {
+	stockCodes := make([]string, len(codes))
+	targets := make([]string, len(codes))
+
+	for i, code := range codes {
+		stockCodes[i], targets[i], _ = strings.Cut(code, ":")
+	}
+
+	return stockCodes, targets
+}
+
+// convertQuotes sets the currency of the listing of every quote and converts the quotes with a target currency to it
+// the quotes are copied, so the cached ones are never modified, and the quotes whose rate is not available are kept
+// in the currency of their listing, explained by the returned notes
+func (s *StockService) convertQuotes(codes []string, targets []string, quotes []*provider.Quote) ([]*provider.Quote, []string) {
+	var notes []string
+
+	converted := make([]*provider.Quote, len(quotes))
+	for i, q := range quotes {
+		c := *q
+		converted[i] = &c
+
+		listing := provider.CurrencyOf(codes[i])
+		c.Currency = listing.Code
+
+		if c.Err != nil || targets[i] == "" {
+			continue
+		}
+
+		target, ok := provider.LookupCurrency(targets[i])
+		if !ok {
+			notes = append(notes, fmt.Sprintf("%s is not a supported currency, %s is shown in %s", strings.ToUpper(targets[i]), c.Symbol, listing.Code))
+			continue
+		}
+		if target.Code == listing.Code || s.FX == nil {
+			continue
+		}
+
+		rate, err := provider.Convert(s.FX, 1, listing, target)
+		if err != nil {
+			log.Printf("error getting the rate of %s to %s from %s: %s", listing.Code, target.Code, s.FX.Name(), err)
+			notes = append(notes, explainRateError(c.Symbol, listing, target, err))
+			continue
+		}
+
+		for _, v := range []*float64{&c.Open, &c.High, &c.Low, &c.Close} {
+			*v *= rate
+		}
+		c.Currency = target.Code
+		c.Rate = rate
+		c.ListingCurrency = listing.Code
+	}
+
+	return converted, notes
+}
+
+// formatPrice formats a price of the quote in its currency, followed by the price in the currency of the listing if it was converted
+func formatPrice(q *provider.Quote, price float64) string {
+	currency := currencyOf(q.Currency)
+	if q.Rate == 0 {
+		return currency.Format(price)
+	}
+
+	return fmt.Sprintf("%s (%s)", currency.Format(price), currencyOf(q.ListingCurrency).Format(price/q.Rate))
+}
+
+// currencyOf returns the currency with the given code, dollars for the quotes without a currency
+func currencyOf(code string) *provider.Currency {
+	if c, ok := provider.LookupCurrency(code); ok {
+		return c
+	}
+
+	c, _ := provider.LookupCurrency("USD")
+	return c
+}
+
+// explainRateError explains why the quote of a stock could not be converted to the target currency
+func explainRateError(symbol string, from *provider.Currency, to *provider.Currency, err error) string {
+	reason := "is not available"
+	if errors.Is(err, provider.ErrUnavailable) || errors.Is(err, provider.ErrRateLimited) {
+		reason = "could not be fetched, please try again later"
+	}
+
+	return fmt.Sprintf("The %s/%s exchange rate %s, %s is shown in %s", from.Code, to.Code, reason, symbol, from.Code)
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"bot/internal/provider"
+	"testing"
+)
+
+func TestGetQuotesInTargetCurrency(t *testing.T) {
+	quotes := provider.NewFakeProvider(map[string]*provider.Quote{
+		"sap.de":  {Symbol: "SAP.DE", Close: 120.50},
+		"vod.uk":  {Symbol: "VOD.UK", Close: 70.12},
+		"7203.jp": {Symbol: "7203.JP", Close: 2350},
+	})
+	fx := provider.NewFakeFXProvider(map[string]float64{"EURUSD": 1.08, "GBPUSD": 1.25})
+
+	s := NewStockService(nil, quotes, nil, nil, fx, WorkerConfig{})
+
+	tests := []struct {
+		codes []string
+		want  string
+	}{
+		{[]string{"sap.de"}, "SAP.DE quote is €120.50 per share"},
+		{[]string{"sap.de:usd"}, "SAP.DE quote is $130.14 (€120.50) per share"},
+		{[]string{"vod.uk:usd"}, "VOD.UK quote is $0.88 (70.12p) per share"},
+		{[]string{"7203.jp"}, "7203.JP quote is ¥2350 per share"},
+		{[]string{"sap.de:xyz"}, "SAP.DE quote is €120.50 per share\nXYZ is not a supported currency, SAP.DE is shown in EUR"},
+		{[]string{"7203.jp:usd"}, "7203.JP quote is ¥2350 per share\nThe JPY/USD exchange rate is not available, 7203.JP is shown in JPY"},
+	}
+
+	for _, tt := range tests {
+		if got, _, _ := s.getQuotes(&stockPayload{StockCodes: tt.codes}); got != tt.want {
+			t.Errorf("getQuotes(%v) = %q, want %q", tt.codes, got, tt.want)
+		}
+	}
+
+	// the cached quotes are never converted
+	if quotes.Quotes["sap.de"].Close != 120.50 {
+		t.Errorf("the quote of the provider was modified: %v", quotes.Quotes["sap.de"].Close)
+	}
+}
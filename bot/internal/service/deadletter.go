@@ -0,0 +1,140 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"github.com/streadway/amqp"
+	"infra"
+	"time"
+)
+
+// DeadLetter is a request dead-lettered to the dlq, Reason is why rabbitmq dead-lettered it, such as rejected
+type DeadLetter struct {
+	RoutingKey string
+	Reason     string
+	Queue      string
+	Count      int64
+	Time       time.Time
+	Body       string
+}
+
+// DeadLetterService inspects the requests dead-lettered to the dlq, and replays them to the bots
+type DeadLetterService struct {
+	Manager *infra.ConnectionManager
+}
+
+// NewDeadLetterService builds a service and injects its dependencies
+func NewDeadLetterService(m *infra.ConnectionManager) *DeadLetterService {
+	return &DeadLetterService{
+		Manager: m,
+	}
+}
+
+// Setup connects to rabbitmq and declares the exchanges and queues of the bot, including the dlq
+func (s *DeadLetterService) Setup() error {
+	if err := setupAMQExchange(s.Manager); err != nil {
+		return errors.New(fmt.Sprintf("error setting up the amq connection and exchange: %s", err))
+	}
+
+	return nil
+}
+
+// Inspect returns up to limit dead-lettered requests, oldest first, leaving them in the dlq
+func (s *DeadLetterService) Inspect(limit int) ([]*DeadLetter, error) {
+	var letters []*DeadLetter
+
+	err := s.Manager.WithChannel(func(ch infra.Channel) error {
+		var last uint64
+		for len(letters) < limit {
+			d, ok, err := ch.Get(deadLetterQueueName, false)
+			if err != nil {
+				return errors.New(fmt.Sprintf("error getting dead-lettered message: %s", err))
+			}
+			if !ok {
+				break
+			}
+
+			letters = append(letters, deadLetterOf(d))
+			last = d.DeliveryTag
+		}
+
+		if last == 0 {
+			return nil
+		}
+
+		// every message got is requeued in its original position
+		if err := ch.Nack(last, true, true); err != nil {
+			return errors.New(fmt.Sprintf("error requeuing dead-lettered messages: %s", err))
+		}
+
+		return nil
+	})
+
+	return letters, err
+}
+
+// Replay publishes up to limit dead-lettered requests back to the exchange with their original routing key, removing them from the dlq
+// they are marked as replayed, so they are answered in their room with a fresh count of attempts
+func (s *DeadLetterService) Replay(limit int) (int, error) {
+	replayed := 0
+
+	err := s.Manager.WithChannel(func(ch infra.Channel) error {
+		for replayed < limit {
+			d, ok, err := ch.Get(deadLetterQueueName, false)
+			if err != nil {
+				return errors.New(fmt.Sprintf("error getting dead-lettered message: %s", err))
+			}
+			if !ok {
+				return nil
+			}
+
+			msg := amqp.Publishing{
+				ContentType:   d.ContentType,
+				CorrelationId: d.CorrelationId,
+				ReplyTo:       d.ReplyTo,
+				DeliveryMode:  amqp.Persistent,
+				Headers:       amqp.Table{replayedHeader: true},
+				Body:          d.Body,
+			}
+
+			if err := ch.Publish(exchangeName, d.RoutingKey, false, false, msg); err != nil {
+				return errors.New(fmt.Sprintf("error replaying message: %s", err))
+			}
+
+			if err := d.Ack(false); err != nil {
+				return errors.New(fmt.Sprintf("error acking dead-lettered message: %s", err))
+			}
+
+			replayed++
+		}
+
+		return nil
+	})
+
+	return replayed, err
+}
+
+// deadLetterOf reads why a message was dead-lettered from its most recent x-death entry
+func deadLetterOf(d amqp.Delivery) *DeadLetter {
+	letter := &DeadLetter{
+		RoutingKey: d.RoutingKey,
+		Body:       string(d.Body),
+	}
+
+	deaths, _ := d.Headers["x-death"].([]interface{})
+	if len(deaths) == 0 {
+		return letter
+	}
+
+	death, ok := deaths[0].(amqp.Table)
+	if !ok {
+		return letter
+	}
+
+	letter.Reason, _ = death["reason"].(string)
+	letter.Queue, _ = death["queue"].(string)
+	letter.Count, _ = death["count"].(int64)
+	letter.Time, _ = death["time"].(time.Time)
+
+	return letter
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"bot/internal/cron"
+	"bot/internal/provider"
+	"bot/internal/repo"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/streadway/amqp"
+	"infra"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	// previousCloseDays is the calendar days of history fetched to find the previous close, covering weekends and holidays
+	previousCloseDays = 10
+	// maxDigestDelay is how late a digest is still posted, when a tick was delayed or failed
+	maxDigestDelay = 5 * time.Minute
+)
+
+// DigestService posts the digests of the configured symbols to the rooms at the times of their schedules
+type DigestService struct {
+	Manager  *infra.ConnectionManager
+	Provider provider.QuoteProvider
+	History  provider.HistoryProvider
+	Repo     repo.DigestRepo
+
+	// publish sends a digest to the server, it is replaced in the tests
+	publish func(body []byte) error
+}
+
+// NewDigestService builds a service and injects its dependencies
+func NewDigestService(m *infra.ConnectionManager, p provider.QuoteProvider, h provider.HistoryProvider, r repo.DigestRepo) *DigestService {
+	s := &DigestService{
+		Manager:  m,
+		Provider: p,
+		History:  h,
+		Repo:     r,
+	}
+
+	s.publish = func(body []byte) error {
+		return s.Manager.Publish(exchangeName, digestKey, amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			Body:         body,
+		})
+	}
+
+	return s
+}
+
+// Run checks the schedules at the start of every minute, it never returns
+// the schedules are read again every minute, so they can be changed without restarting the bot
+func (s *DigestService) Run() {
+	last := time.Now().Truncate(time.Minute)
+	for {
+		now := time.Now()
+		time.Sleep(now.Truncate(time.Minute).Add(time.Minute).Sub(now))
+
+		last = s.catchUp(context.Background(), last, time.Now().Truncate(time.Minute))
+	}
+}
+
+// catchUp ticks every minute after last up to at, so a slow tick does not skip a minute, and returns the last minute ticked
+// a minute that fails is ticked again by the next call, until it is later than maxDigestDelay
+func (s *DigestService) catchUp(ctx context.Context, last time.Time, at time.Time) time.Time {
+	if oldest := at.Add(-maxDigestDelay); last.Before(oldest) {
+		log.Printf("error running digests: skipping the minutes from %s to %s", last.Add(time.Minute), oldest)
+		last = oldest
+	}
+
+	for minute := last.Add(time.Minute); !minute.After(at); minute = minute.Add(time.Minute) {
+		if err := s.tick(ctx, minute); err != nil {
+			log.Printf("error running digests: %s", err)
+			return last
+		}
+		last = minute
+	}
+
+	return last
+}
+
+// tick posts the digests whose schedule runs at the given minute
+// a digest that cannot be published is not marked as run, and an error is returned so the minute is ticked again
+func (s *DigestService) tick(ctx context.Context, at time.Time) error {
+	schedules, err := s.Repo.GetSchedules(ctx)
+	if err != nil {
+		return err
+	}
+
+	var failed error
+	for _, d := range schedules {
+		schedule, err := cron.Parse(d.Cron)
+		if err != nil {
+			log.Printf("error parsing digest schedule %d: %s", d.ID, err)
+			continue
+		}
+
+		loc, err := time.LoadLocation(d.Timezone)
+		if err != nil {
+			log.Printf("error loading the time zone of digest schedule %d: %s", d.ID, err)
+			continue
+		}
+
+		local := at.In(loc)
+		if !schedule.Matches(local) {
+			continue
+		}
+
+		ran, err := s.Repo.MarkRun(ctx, d.ID, at)
+		if err != nil {
+			log.Printf("error marking digest schedule %d: %s", d.ID, err)
+			continue
+		}
+		if !ran {
+			continue
+		}
+
+		if err := s.post(d, local); err != nil {
+			if err := s.Repo.ClearRun(ctx, d.ID, at); err != nil {
+				log.Printf("error clearing the run of digest schedule %d: %s", d.ID, err)
+			}
+			failed = errors.New(fmt.Sprintf("error posting digest %d: %s", d.ID, err))
+			continue
+		}
+
+		log.Printf("Digest %q sent to room %s, next one at %s", d.Name, d.RoomID, schedule.Next(local))
+	}
+
+	return failed
+}
+
+// post builds the digest of the schedule and publishes it as an unsolicited quote for its room
+func (s *DigestService) post(d *repo.DigestSchedule, at time.Time) error {
+	qpl := quotePayload{
+		RoomID:     d.RoomID,
+		StockQuote: s.digest(d, at),
+	}
+
+	body, err := json.Marshal(qpl)
+	if err != nil {
+		return errors.New(fmt.Sprintf("error marshaling payload: %s", err))
+	}
+
+	return s.publish(body)
+}
+
+// digest formats a table with the last price of every symbol and its change since the previous close
+func (s *DigestService) digest(d *repo.DigestSchedule, at time.Time) string {
+	title := fmt.Sprintf("%s digest, %s", d.Name, at.Format("Mon Jan 2 15:04 MST"))
+
+	quotes, err := s.Provider.GetQuotes(d.Symbols)
+	if err != nil {
+		log.Printf("error getting digest quotes from %s: %s", s.Provider.Name(), err)
+		return fmt.Sprintf("%s\n%s", title, explainError(strings.ToUpper(strings.Join(d.Symbols, ", ")), err))
+	}
+
+	lines := []string{title, fmt.Sprintf("%-12s %-10s %s", "Symbol", "Price", "Change")}
+	for i, q := range quotes {
+		if q.Err != nil {
+			lines = append(lines, fmt.Sprintf("%-12s %s", q.Symbol, formatQuoteError(q)))
+			continue
+		}
+
+		c := provider.CurrencyOf(d.Symbols[i])
+
+		change := "n/a"
+		if prev, ok := s.previousClose(d.Symbols[i], q, at); ok && prev != 0 {
+			change = fmt.Sprintf("%+.*f (%+.2f%%)", c.Decimals, q.Close-prev, (q.Close-prev)/prev*100)
+		}
+
+		lines = append(lines, fmt.Sprintf("%-12s %-10s %s", q.Symbol, c.Format(q.Close), change))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// previousClose returns the close of the last session before the session of the quote
+func (s *DigestService) previousClose(code string, q *provider.Quote, at time.Time) (float64, bool) {
+	bars, err := s.History.GetHistory(code, at.AddDate(0, 0, -previousCloseDays), at)
+	if err != nil {
+		log.Printf("error getting the previous close of %s: %s", q.Symbol, err)
+		return 0, false
+	}
+
+	// without the date of the quote, the last bar is taken as its session
+	if q.Date == "" {
+		if len(bars) < 2 {
+			return 0, false
+		}
+		return bars[len(bars)-2].Close, true
+	}
+
+	for i := len(bars) - 1; i >= 0; i-- {
+		if bars[i].Date < q.Date {
+			return bars[i].Close, true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"bot/internal/provider"
+	"bot/internal/repo"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeDigestRepo keeps the schedules in memory
+type fakeDigestRepo struct {
+	schedules []*repo.DigestSchedule
+	lastRun   map[int64]time.Time
+}
+
+func (r *fakeDigestRepo) GetSchedules(ctx context.Context) ([]*repo.DigestSchedule, error) {
+	return r.schedules, nil
+}
+
+func (r *fakeDigestRepo) MarkRun(ctx context.Context, id int64, at time.Time) (bool, error) {
+	if !r.lastRun[id].Before(at) {
+		return false, nil
+	}
+	r.lastRun[id] = at
+	return true, nil
+}
+
+func (r *fakeDigestRepo) ClearRun(ctx context.Context, id int64, at time.Time) error {
+	if r.lastRun[id].Equal(at) {
+		r.lastRun[id] = at.Add(-time.Minute)
+	}
+	return nil
+}
+
+// fakeHistory answers the same bars for every code
+type fakeHistory []*provider.Bar
+
+func (h fakeHistory) GetHistory(code string, from time.Time, to time.Time) ([]*provider.Bar, error) {
+	return h, nil
+}
+
+func TestDigestTick(t *testing.T) {
+	digests := &fakeDigestRepo{
+		schedules: []*repo.DigestSchedule{
+			{ID: 1, RoomID: "general", Name: "Market open", Cron: "30 9 * * 1-5", Timezone: "America/New_York", Symbols: []string{"aapl.us"}},
+			{ID: 2, RoomID: "general", Name: "Market close", Cron: "0 16 * * 1-5", Timezone: "America/New_York", Symbols: []string{"aapl.us"}},
+		},
+		lastRun: make(map[int64]time.Time),
+	}
+
+	p := provider.NewFakeProvider(nil)
+	history := fakeHistory{
+		{Date: "2023-10-13", Close: 178.00},
+		{Date: "2023-10-16", Close: 178.85},
+	}
+
+	var published []quotePayload
+	s := NewDigestService(nil, p, history, digests)
+	s.publish = func(body []byte) error {
+		var pl quotePayload
+		if err := json.Unmarshal(body, &pl); err != nil {
+			t.Fatalf("failed to unmarshal the digest: %s", err)
+		}
+		published = append(published, pl)
+		return nil
+	}
+
+	// 09:30 in New York
+	at := time.Date(2023, 10, 16, 13, 30, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		if err := s.tick(context.Background(), at); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	if len(published) != 1 {
+		t.Fatalf("expected a single digest, got %d", len(published))
+	}
+
+	digest := published[0]
+	if digest.RoomID != "general" || digest.CorrelationID != "" {
+		t.Errorf("expected an unsolicited post for the general room, got %+v", digest)
+	}
+	if !strings.HasPrefix(digest.StockQuote, "Market open digest, Mon Oct 16 09:30 EDT") {
+		t.Errorf("unexpected title: %q", digest.StockQuote)
+	}
+	if !strings.Contains(digest.StockQuote, "AAPL.US      $178.85    +0.85 (+0.48%)") {
+		t.Errorf("expected the change since the previous close, got %q", digest.StockQuote)
+	}
+}
+
+func TestDigestCatchUp(t *testing.T) {
+	digests := &fakeDigestRepo{
+		schedules: []*repo.DigestSchedule{
+			{ID: 1, RoomID: "general", Name: "Market open", Cron: "30 9 * * 1-5", Timezone: "America/New_York", Symbols: []string{"aapl.us"}},
+		},
+		lastRun: make(map[int64]time.Time),
+	}
+
+	published := 0
+	failing := true
+	s := NewDigestService(nil, provider.NewFakeProvider(nil), fakeHistory{}, digests)
+	s.publish = func(body []byte) error {
+		if failing {
+			return errors.New("channel closed")
+		}
+		published++
+		return nil
+	}
+
+	// the tick of 09:30 in New York was delayed past the next minute
+	before := time.Date(2023, 10, 16, 13, 29, 0, 0, time.UTC)
+	at := time.Date(2023, 10, 16, 13, 31, 0, 0, time.UTC)
+
+	// a failed digest is posted by the next call
+	last := s.catchUp(context.Background(), before, at)
+	if !last.Equal(before) || published != 0 {
+		t.Fatalf("expected the failed minute to be ticked again, got %s", last)
+	}
+
+	failing = false
+	last = s.catchUp(context.Background(), last, at)
+	if !last.Equal(at) || published != 1 {
+		t.Errorf("expected the delayed digest to be posted once, got %d digests until %s", published, last)
+	}
+
+	// the minutes later than the max delay are skipped
+	published = 0
+	digests.lastRun = make(map[int64]time.Time)
+	last = s.catchUp(context.Background(), before, at.Add(maxDigestDelay))
+	if published != 0 || !last.Equal(at.Add(maxDigestDelay)) {
+		t.Errorf("expected the late digest to be skipped, got %d digests until %s", published, last)
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"bot/internal/provider"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultHistoryRange = "1m"
+
+// historyRange is the period of a history request, such as 5d (sessions), 2w, 6m or 1y
+type historyRange struct {
+	value    int
+	unit     byte
+	original string
+}
+
+// parseHistoryRange parses a range made of a number and a unit: d (trading sessions), w (weeks), m (months) or y (years)
+func parseHistoryRange(s string) (*historyRange, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		s = defaultHistoryRange
+	}
+
+	if len(s) < 2 || !strings.ContainsRune("dwmy", rune(s[len(s)-1])) {
+		return nil, errors.New(fmt.Sprintf("invalid history range: %q", s))
+	}
+
+	value, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || value <= 0 {
+		return nil, errors.New(fmt.Sprintf("invalid history range: %q", s))
+	}
+
+	return &historyRange{value: value, unit: s[len(s)-1], original: s}, nil
+}
+
+// start returns the first date of the range ending at the given date
+// sessions are counted back with enough calendar days to cover weekends and holidays, the extra bars are trimmed later
+func (r *historyRange) start(end time.Time) time.Time {
+	switch r.unit {
+	case 'd':
+		return end.AddDate(0, 0, -(r.value*7/5 + 7))
+	case 'w':
+		return end.AddDate(0, 0, -7*r.value)
+	case 'm':
+		return end.AddDate(0, -r.value, 0)
+	default:
+		return end.AddDate(-r.value, 0, 0)
+	}
+}
+
+// trim keeps the last sessions of a range counted in sessions
+func (r *historyRange) trim(bars []*provider.Bar) []*provider.Bar {
+	if r.unit == 'd' && len(bars) > r.value {
+		return bars[len(bars)-r.value:]
+	}
+
+	return bars
+}
+
+// getHistory fetches the daily history of the requested code and summarizes it as the reply to post in the room
+// the provider failure, if any, is returned along with the reply
+func (s *StockService) getHistory(spl *stockPayload) (string, error) {
+	if len(spl.StockCodes) == 0 {
+		return "Missing the stock code of the history request", nil
+	}
+	code := spl.StockCodes[0]
+	symbol := strings.ToUpper(code)
+
+	rng, err := parseHistoryRange(spl.Range)
+	if err != nil {
+		return fmt.Sprintf("%s. It should be something like /history=aapl.us,1m", err), nil
+	}
+
+	end := time.Now().UTC()
+
+	bars, err := s.History.GetHistory(code, rng.start(end), end)
+	if err != nil {
+		log.Printf("error getting the history of %s: %s", symbol, err)
+		return explainError(symbol, err), err
+	}
+
+	bars = rng.trim(bars)
+	if len(bars) == 0 {
+		return fmt.Sprintf("%s has no sessions in the last %s", symbol, rng.original), nil
+	}
+
+	return summarizeHistory(symbol, rng.original, bars), nil
+}
+
+// summarizeHistory computes the period high and low, the change and the average volume of the bars, oldest first
+func summarizeHistory(symbol string, rng string, bars []*provider.Bar) string {
+	first, last := bars[0], bars[len(bars)-1]
+
+	high, low := first.High, first.Low
+	var volume int64
+	for _, bar := range bars {
+		if bar.High > high {
+			high = bar.High
+		}
+		if bar.Low < low {
+			low = bar.Low
+		}
+		volume += bar.Volume
+	}
+
+	change := 0.0
+	if first.Open != 0 {
+		change = (last.Close - first.Open) / first.Open * 100
+	}
+
+	c := provider.CurrencyOf(symbol)
+
+	return fmt.Sprintf(
+		"%s %s (%s to %s, %d sessions): %s to %s (%+.2f%%), high %s, low %s, avg volume %d",
+		symbol, rng, first.Date, last.Date, len(bars), c.Format(first.Open), c.Format(last.Close), change, c.Format(high), c.Format(low), volume/int64(len(bars)),
+	)
+}
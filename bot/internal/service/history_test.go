@@ -0,0 +1,44 @@
+package service
+
+import (
+	"bot/internal/provider"
+	"testing"
+)
+
+func TestParseHistoryRange(t *testing.T) {
+	for _, valid := range []string{"5d", "2W", "6m", "1y", ""} {
+		if _, err := parseHistoryRange(valid); err != nil {
+			t.Errorf("Expected %q to be a valid range, got %s", valid, err)
+		}
+	}
+
+	for _, invalid := range []string{"m", "0d", "1h", "-1m", "abc"} {
+		if _, err := parseHistoryRange(invalid); err == nil {
+			t.Errorf("Expected %q to be an invalid range", invalid)
+		}
+	}
+}
+
+func TestSummarizeHistory(t *testing.T) {
+	bars := []*provider.Bar{
+		{Date: "2023-10-12", Open: 100, High: 105, Low: 98, Close: 104, Volume: 1000},
+		{Date: "2023-10-13", Open: 104, High: 112, Low: 101, Close: 110, Volume: 3000},
+	}
+
+	got := summarizeHistory("AAPL.US", "5d", bars)
+	want := "AAPL.US 5d (2023-10-12 to 2023-10-13, 2 sessions): $100.00 to $110.00 (+10.00%), high $112.00, low $98.00, avg volume 2000"
+
+	if got != want {
+		t.Errorf("summarizeHistory() = %q, want %q", got, want)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	if got := sparkline([]float64{1, 2, 3, 4, 5, 6, 7, 8}); got != "▁▂▃▄▅▆▇█" {
+		t.Errorf("sparkline() = %q, want the blocks in ascending order", got)
+	}
+
+	if got := sparkline([]float64{5, 5, 5}); got != "▅▅▅" {
+		t.Errorf("sparkline() = %q, want flat values in the middle block", got)
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"bot/internal/provider"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// getFX fetches the rate of a currency pair, such as eurusd, and formats it as the reply to post in the room
+// the provider failure, if any, is returned along with the reply
+func (s *StockService) getFX(spl *stockPayload) (string, error) {
+	if len(spl.StockCodes) == 0 {
+		return "Missing the currency pair of the fx request", nil
+	}
+	pair := strings.ToLower(spl.StockCodes[0])
+	name := fmt.Sprintf("%s/%s", strings.ToUpper(pair[:len(pair)/2]), strings.ToUpper(pair[len(pair)/2:]))
+
+	q, err := s.getPair(pair)
+	if err != nil {
+		return explainPairError(name, "currency pair", "/fx=eurusd", err), err
+	}
+
+	// the rates of the currencies worth much less than the base, such as usdjpy, need fewer decimals
+	decimals := 4
+	if q.Close >= 100 {
+		decimals = 2
+	}
+
+	return fmt.Sprintf("%s is %.*f (%s), range %.*f - %.*f",
+		name, decimals, q.Close, formatChange(q), decimals, q.Low, decimals, q.High), nil
+}
+
+// getCrypto fetches the price of a cryptocurrency in a currency, such as btc:usd, and formats it as the reply to post in the room
+// the provider failure, if any, is returned along with the reply
+func (s *StockService) getCrypto(spl *stockPayload) (string, error) {
+	if len(spl.StockCodes) == 0 {
+		return "Missing the cryptocurrency of the crypto request", nil
+	}
+	code, target, _ := strings.Cut(strings.ToLower(spl.StockCodes[0]), ":")
+	if target == "" {
+		target = "usd"
+	}
+	name := strings.ToUpper(code)
+
+	q, err := s.getPair(code + target)
+	if err != nil {
+		return explainPairError(fmt.Sprintf("%s in %s", name, strings.ToUpper(target)), "cryptocurrency", "/crypto=btc", err), err
+	}
+
+	currency, ok := provider.LookupCurrency(target)
+	if !ok {
+		currency = &provider.Currency{Code: strings.ToUpper(target), Symbol: " " + strings.ToUpper(target), Suffix: true}
+	}
+
+	// the coins worth less than a unit of the currency need more decimals
+	c := *currency
+	c.Decimals = 2
+	if q.Close < 1 {
+		c.Decimals = 6
+	}
+
+	return fmt.Sprintf("%s is %s (%s), range %s - %s", name, c.Format(q.Close), formatChange(q), c.Format(q.Low), c.Format(q.High)), nil
+}
+
+// getPair fetches the quote of a currency or crypto pair
+func (s *StockService) getPair(pair string) (*provider.Quote, error) {
+	quotes, err := s.Provider.GetQuotes([]string{pair})
+	if err != nil {
+		log.Printf("error getting the quote of %s from %s: %s", pair, s.Provider.Name(), err)
+		return nil, err
+	}
+	if len(quotes) != 1 {
+		return nil, fmt.Errorf("%w: expected the quote of %s", provider.ErrMalformed, pair)
+	}
+	if quotes[0].Err != nil {
+		log.Printf("error getting the quote of %s from %s: %s", pair, quotes[0].Source, quotes[0].Err)
+		return nil, quotes[0].Err
+	}
+
+	return quotes[0], nil
+}
+
+// formatChange formats the change of the quote since the open of the session, as a percentage
+func formatChange(q *provider.Quote) string {
+	if q.Open == 0 {
+		return "no change data"
+	}
+
+	return fmt.Sprintf("%+.2f%% today", (q.Close-q.Open)/q.Open*100)
+}
+
+// explainPairError explains why the quote of a currency or crypto pair could not be fetched
+func explainPairError(name string, kind string, usage string, err error) string {
+	switch {
+	case errors.Is(err, provider.ErrStockNotFound):
+		return fmt.Sprintf("%s is not a supported %s. It should be something like %s", name, kind, usage)
+	case errors.Is(err, provider.ErrRateLimited):
+		return fmt.Sprintf("Too many rates requested, the rate of %s is not available. Please try again in a few minutes", name)
+	case errors.Is(err, provider.ErrUnavailable):
+		return fmt.Sprintf("The rate provider is unavailable, the rate of %s could not be fetched. Please try again later", name)
+	default:
+		return fmt.Sprintf("Failed to get the rate of %s. Please try again later", name)
+	}
+}
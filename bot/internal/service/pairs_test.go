@@ -0,0 +1,36 @@
+package service
+
+import (
+	"bot/internal/provider"
+	"testing"
+)
+
+func TestGetPairs(t *testing.T) {
+	quotes := provider.NewFakeProvider(map[string]*provider.Quote{
+		"eurusd":  {Symbol: "EURUSD", Open: 1.08, High: 1.0861, Low: 1.0795, Close: 1.0842},
+		"usdjpy":  {Symbol: "USDJPY", Open: 149.80, High: 150.12, Low: 149.31, Close: 149.52},
+		"btcusd":  {Symbol: "BTCUSD", Open: 66000, High: 68010.5, Low: 65870, Close: 67123.45},
+		"dogeeur": {Symbol: "DOGEEUR", Open: 0.15, High: 0.16, Low: 0.149, Close: 0.153421},
+	})
+
+	s := NewStockService(nil, quotes, nil, nil, nil, WorkerConfig{})
+
+	tests := []struct {
+		get  func(spl *stockPayload) (string, error)
+		code string
+		want string
+	}{
+		{s.getFX, "eurusd", "EUR/USD is 1.0842 (+0.39% today), range 1.0795 - 1.0861"},
+		{s.getFX, "usdjpy", "USD/JPY is 149.52 (-0.19% today), range 149.31 - 150.12"},
+		{s.getFX, "eurxyz", "EUR/XYZ is not a supported currency pair. It should be something like /fx=eurusd"},
+		{s.getCrypto, "btc:usd", "BTC is $67123.45 (+1.70% today), range $65870.00 - $68010.50"},
+		{s.getCrypto, "doge:eur", "DOGE is €0.153421 (+2.28% today), range €0.149000 - €0.160000"},
+		{s.getCrypto, "xyz:usd", "XYZ in USD is not a supported cryptocurrency. It should be something like /crypto=btc"},
+	}
+
+	for _, tt := range tests {
+		if got, _ := tt.get(&stockPayload{StockCodes: []string{tt.code}}); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
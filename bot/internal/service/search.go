@@ -0,0 +1,77 @@
+package service
+
+import (
+	"bot/internal/catalog"
+	"bot/internal/provider"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	maxSearchResults = 10
+	maxSuggestions   = 3
+)
+
+// search looks up the query in the symbols catalog and formats the matches as the reply to post in the room
+func (s *StockService) search(spl *stockPayload) (string, []*catalog.Symbol) {
+	if s.Catalog == nil {
+		return "The symbol search is not available", nil
+	}
+
+	symbols := s.Catalog.Search(spl.Query, maxSearchResults)
+	if len(symbols) == 0 {
+		return fmt.Sprintf("No symbols match %q", spl.Query), nil
+	}
+
+	lines := []string{fmt.Sprintf("Symbols matching %q:", spl.Query)}
+	for _, sym := range symbols {
+		lines = append(lines, fmt.Sprintf("%-12s %s", strings.ToUpper(sym.Code), describe(sym)))
+	}
+
+	return strings.Join(lines, "\n"), symbols
+}
+
+// suggest proposes the symbols of the catalog close to the codes that were not found
+func (s *StockService) suggest(codes []string, quotes []*provider.Quote, err error) string {
+	if s.Catalog == nil {
+		return ""
+	}
+
+	var notFound []string
+	switch {
+	case errors.Is(err, provider.ErrStockNotFound):
+		notFound = codes
+	case err == nil:
+		for i, q := range quotes {
+			if i < len(codes) && errors.Is(q.Err, provider.ErrStockNotFound) {
+				notFound = append(notFound, codes[i])
+			}
+		}
+	}
+
+	var lines []string
+	for _, code := range notFound {
+		symbols := s.Catalog.Suggest(code, maxSuggestions)
+		if len(symbols) == 0 {
+			continue
+		}
+
+		options := make([]string, len(symbols))
+		for i, sym := range symbols {
+			options[i] = fmt.Sprintf("%s (%s)", strings.ToUpper(sym.Code), sym.Name)
+		}
+		lines = append(lines, fmt.Sprintf("Did you mean %s instead of %s?", strings.Join(options, " or "), strings.ToUpper(code)))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// describe returns the name of the company of the symbol and its exchange
+func describe(sym *catalog.Symbol) string {
+	if sym.Exchange == "" {
+		return sym.Name
+	}
+
+	return fmt.Sprintf("%s (%s)", sym.Name, sym.Exchange)
+}
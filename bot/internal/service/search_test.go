@@ -0,0 +1,34 @@
+package service
+
+import (
+	"bot/internal/catalog"
+	"bot/internal/provider"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSuggestNotFoundCodes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "symbols.csv")
+	if err := os.WriteFile(path, []byte("code,name,exchange\naapl.us,Apple Inc.,NASDAQ\nsap.de,SAP SE,XETRA\n"), 0o644); err != nil {
+		t.Fatalf("failed to write the symbols file: %s", err)
+	}
+
+	symbols, err := catalog.Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	s := NewStockService(nil, provider.NewFakeProvider(nil), nil, symbols, nil, WorkerConfig{})
+
+	reply, _, _ := s.getQuotes(&stockPayload{StockCodes: []string{"aapl.us", "sap.us"}})
+	if !strings.Contains(reply, "Did you mean SAP.DE (SAP SE) instead of SAP.US?") {
+		t.Errorf("expected a suggestion for sap.us, got %q", reply)
+	}
+
+	reply, found := s.search(&stockPayload{Query: "apple"})
+	if len(found) != 1 || !strings.Contains(reply, "AAPL.US      Apple Inc. (NASDAQ)") {
+		t.Errorf("unexpected search reply: %q", reply)
+	}
+}
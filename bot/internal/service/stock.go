@@ -1,80 +1,258 @@
 package service
 
 import (
+	"bot/internal/catalog"
+	"bot/internal/provider"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/streadway/amqp"
+	"infra"
 	"log"
 	"strings"
+	"time"
 )
 
-type StockService struct{}
+type StockService struct {
+	Manager  *infra.ConnectionManager
+	Provider provider.QuoteProvider
+	History  provider.HistoryProvider
+	Catalog  *catalog.Catalog
+	FX       provider.FXProvider
+	Pool     WorkerConfig
+
+	// publish sends a reply to the server that requested it, and retry sends a request to the retry queue, they are replaced in the tests
+	publish func(replyTo string, correlationID string, body []byte) error
+	retry   func(message amqp.Delivery, attempt int) error
+}
 
 type stockPayload struct {
-	StockCode string `json:"stockCode"`
+	CorrelationID string     `json:"correlationID"`
+	RequesterID   string     `json:"requesterID"`
+	RoomID        string     `json:"roomID"`
+	StockCodes    []string   `json:"stockCodes"`
+	StockCode     string     `json:"stockCode,omitempty"` // sent by the servers that only request a single code
+	Range         string     `json:"range,omitempty"`     // the period of the history requests
+	Query         string     `json:"query,omitempty"`     // the text of the symbol searches
+	Timestamp     *time.Time `json:"timestamp"`
 }
 
+// quotePayload is the reply to a stock request, StockQuote is the formatted reply and Quotes the structured quotes
+// the posts not answering a request, such as the digests, have no correlation id and carry the room to post them to,
+// the replies carry the room of their request too
 type quotePayload struct {
-	StockQuote string `json:"stockQuote"`
+	CorrelationID string            `json:"correlationID"`
+	RoomID        string            `json:"roomID,omitempty"`
+	StockQuote    string            `json:"stockQuote"`
+	Quotes        []*provider.Quote `json:"quotes,omitempty"`
+	Symbols       []*catalog.Symbol `json:"symbols,omitempty"`
+}
+
+// answer is the reply to a request, Transient is the provider failure a later attempt may not hit
+type answer struct {
+	Body          []byte
+	ReplyTo       string
+	CorrelationID string
+	Transient     error
 }
 
-// NewStockService builds a service
-func NewStockService() *StockService {
-	return &StockService{}
+// NewStockService builds a service and injects its dependencies
+func NewStockService(m *infra.ConnectionManager, p provider.QuoteProvider, h provider.HistoryProvider, c *catalog.Catalog, fx provider.FXProvider, pool WorkerConfig) *StockService {
+	s := &StockService{
+		Manager:  m,
+		Provider: p,
+		History:  h,
+		Catalog:  c,
+		FX:       fx,
+		Pool:     pool,
+	}
+
+	s.publish = func(replyTo string, correlationID string, body []byte) error {
+		return publishAMQMessage(s.Manager, replyTo, correlationID, body)
+	}
+	s.retry = func(message amqp.Delivery, attempt int) error {
+		return publishRetry(s.Manager, message, attempt, s.Pool.RetryDelay)
+	}
+
+	return s
 }
 
 // ProcessMessages subscribes to the rabbitmq exchange <stockchat> to get stock codes
-// and publishes back the corresponding quotes fetched from the stooq api
-func (s *StockService) ProcessMessages() {
-	conn, ch, err := setupAMQExchange()
-	defer conn.Close()
-	defer ch.Close()
+// and publishes back the corresponding quotes fetched from the quote provider, correlated to the request they answer
+// the history and chart requests, routed with their own keys, are answered with a summary or a sparkline of the daily history of the code
+// the searches with the symbols of the catalog matching the query, and the fx and crypto requests with their rates
+// the requests are processed concurrently by the worker pool, when ctx is done it stops consuming and returns once the requests in flight are answered
+// otherwise it only returns when the rabbitmq connection cannot be established, or it is closed
+func (s *StockService) ProcessMessages(ctx context.Context) error {
+	if err := setupAMQExchange(s.Manager); err != nil {
+		return errors.New(fmt.Sprintf("error setting up the amq connection and exchange: %s", err))
+	}
 
+	messages, err := consumeAMQMessages(s.Manager, s.Pool.Prefetch, ctx.Done())
 	if err != nil {
-		log.Printf("error setting up the amq connection and exchange: %s", err)
+		return errors.New(fmt.Sprintf("error consuming messages: %s", err))
 	}
+	removeLegacyQueue(s.Manager)
+
+	log.Printf("Processing messages with %d workers, prefetch %d\n", s.Pool.Workers, s.Pool.Prefetch)
+	s.serve(messages)
 
-	messages, err := consumeAMQMessages(ch)
+	if ctx.Err() != nil {
+		log.Println("Stopped consuming, the requests in flight were answered")
+		return nil
+	}
+
+	return infra.ErrClosed
+}
+
+// reply builds the answer to a request
+// a request replayed from the dlq is answered as a post to its room, the server that sent it no longer waits for it
+func (s *StockService) reply(message amqp.Delivery) (*answer, error) {
+	var spl stockPayload
+	if err := json.Unmarshal(message.Body, &spl); err != nil {
+		return nil, errors.New(fmt.Sprintf("error unmarshaling payload: %s", err))
+	}
+
+	if len(spl.StockCodes) == 0 && spl.StockCode != "" {
+		spl.StockCodes = []string{spl.StockCode}
+	}
+
+	a := &answer{
+		ReplyTo:       message.ReplyTo,
+		CorrelationID: message.CorrelationId,
+	}
+	if a.CorrelationID == "" {
+		a.CorrelationID = spl.CorrelationID
+	}
+
+	// the reply carries the room of the request, so it is still posted if the server stopped waiting for it
+	// after the retries of a long outage
+	qpl := quotePayload{RoomID: spl.RoomID}
+	if replayed, _ := message.Headers[replayedHeader].(bool); replayed {
+		a.ReplyTo, a.CorrelationID = digestKey, ""
+	}
+	qpl.CorrelationID = a.CorrelationID
+
+	var err error
+	switch message.RoutingKey {
+	case historyKey:
+		qpl.StockQuote, err = s.getHistory(&spl)
+	case chartKey:
+		qpl.StockQuote, err = s.getChart(&spl)
+	case searchKey:
+		qpl.StockQuote, qpl.Symbols = s.search(&spl)
+	case fxKey:
+		qpl.StockQuote, err = s.getFX(&spl)
+	case cryptoKey:
+		qpl.StockQuote, err = s.getCrypto(&spl)
+	default:
+		qpl.StockQuote, qpl.Quotes, err = s.getQuotes(&spl)
+	}
+	a.Transient = transientError(nil, err)
+
+	body, err := json.Marshal(qpl)
 	if err != nil {
-		log.Printf("error consuming messages: %s", err)
+		return nil, errors.New(fmt.Sprintf("error marshaling payload: %s", err))
 	}
+	a.Body = body
 
-	for message := range messages {
-		log.Printf("Stock received: %s\n", string(message.Body))
+	return a, nil
+}
 
-		var spl stockPayload
-		err := json.Unmarshal(message.Body, &spl)
-		if err != nil {
-			log.Printf("error unmarshaling payload: %s", err)
-			return
-		}
+// getQuotes fetches the quotes of the requested codes and formats them as the reply to post in the room
+// codes with a target currency, such as sap.de:usd, are converted to it
+// the provider failure worth retrying, if any, is returned along with the reply
+func (s *StockService) getQuotes(spl *stockPayload) (string, []*provider.Quote, error) {
+	codes, targets := splitTargets(spl.StockCodes)
 
-		var quote string
+	quotes, err := s.Provider.GetQuotes(codes)
+	if err != nil {
+		log.Printf("error getting stock quotes from %s: %s", s.Provider.Name(), err)
+	}
 
-		stockQuote, err := getStockQuote(spl.StockCode)
-		if err == nil {
-			quote = fmt.Sprintf("%s quote is $%.2f per share", strings.ToUpper(spl.StockCode), stockQuote)
-		} else {
-			log.Printf("error getting stock quote from stooq: %s", err)
+	var notes []string
+	if err == nil {
+		quotes, notes = s.convertQuotes(codes, targets, quotes)
+	}
 
-			if err.Error() == "stock code not found" {
-				quote = fmt.Sprintf("%s is not a valid stock code. Please check stooq.com for the stock list", strings.ToUpper(spl.StockCode))
-			}
-		}
+	reply := formatQuotes(codes, quotes, err)
+	if suggestions := s.suggest(codes, quotes, err); suggestions != "" {
+		notes = append(notes, suggestions)
+	}
+	if len(notes) > 0 {
+		reply = fmt.Sprintf("%s\n%s", reply, strings.Join(notes, "\n"))
+	}
 
-		qpl := quotePayload{
-			StockQuote: quote,
+	return reply, quotes, transientError(quotes, err)
+}
+
+// transientError returns the failure of the provider, or of any of the quotes, that a later attempt may not hit
+// such as an outage or a rate limit, unlike the codes that were not found
+func transientError(quotes []*provider.Quote, err error) error {
+	if isTransient(err) {
+		return err
+	}
+
+	for _, q := range quotes {
+		if q != nil && isTransient(q.Err) {
+			return q.Err
 		}
+	}
+
+	return nil
+}
+
+func isTransient(err error) bool {
+	return errors.Is(err, provider.ErrUnavailable) || errors.Is(err, provider.ErrRateLimited)
+}
 
-		body, err := json.Marshal(qpl)
-		if err != nil {
-			log.Printf("error unmarshaling payload: %s", err)
-			return
+// formatQuotes formats the quotes of the stock codes as the reply to post in the room, or the failure to fetch them
+// a single code is answered with a sentence, several codes with a table that includes the codes that failed
+func formatQuotes(stockCodes []string, quotes []*provider.Quote, err error) string {
+	if err != nil {
+		return explainError(strings.ToUpper(strings.Join(stockCodes, ", ")), err)
+	}
+
+	if len(quotes) == 1 {
+		q := quotes[0]
+		if q.Err == nil {
+			return fmt.Sprintf("%s quote is %s per share", q.Symbol, formatPrice(q, q.Close))
 		}
+		return formatQuoteError(q)
+	}
 
-		if err := publishAMQMessage(ch, body); err != nil {
-			log.Printf("error publishing to the exchange: %s", err)
+	lines := []string{fmt.Sprintf("%-12s %s", "Symbol", "Quote")}
+	for _, q := range quotes {
+		value := formatPrice(q, q.Close)
+		if q.Err != nil {
+			value = formatQuoteError(q)
 		}
+		lines = append(lines, fmt.Sprintf("%-12s %s", q.Symbol, value))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// formatQuoteError explains why the quote of a stock code could not be fetched
+func formatQuoteError(q *provider.Quote) string {
+	log.Printf("error getting stock quote of %s from %s: %s", q.Symbol, q.Source, q.Err)
+
+	return explainError(q.Symbol, q.Err)
+}
 
-		log.Printf("Quote sent: %s\n", string(body))
+// explainError maps every class of provider error to the reply explaining it to the users
+func explainError(symbols string, err error) string {
+	switch {
+	case errors.Is(err, provider.ErrStockNotFound):
+		return fmt.Sprintf("%s is not a valid stock code. Please check stooq.com for the stock list", symbols)
+	case errors.Is(err, provider.ErrRateLimited):
+		return fmt.Sprintf("Too many quotes requested, the quote of %s is not available. Please try again in a few minutes", symbols)
+	case errors.Is(err, provider.ErrMalformed):
+		return fmt.Sprintf("The quote provider answered an unexpected response for %s. Please try again later", symbols)
+	case errors.Is(err, provider.ErrUnavailable):
+		return fmt.Sprintf("The quote provider is unavailable, the quote of %s could not be fetched. Please try again later", symbols)
+	default:
+		return fmt.Sprintf("Failed to get the quote of %s. Please try again later", symbols)
 	}
 }
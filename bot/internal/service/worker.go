@@ -0,0 +1,150 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"github.com/streadway/amqp"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	workersEnv         = "BOT_WORKERS"
+	prefetchEnv        = "BOT_PREFETCH"
+	maxAttemptsEnv     = "BOT_MAX_ATTEMPTS"
+	retryDelayEnv      = "BOT_RETRY_DELAY"
+	defaultWorkers     = 4
+	defaultMaxAttempts = 3
+	defaultRetryDelay  = 5 * time.Second
+)
+
+// WorkerConfig sizes the pool processing the requests, Prefetch bounds the deliveries rabbitmq hands out before they are acked
+// the requests failing transiently are retried after RetryDelay, up to MaxAttempts attempts
+type WorkerConfig struct {
+	Workers     int
+	Prefetch    int
+	MaxAttempts int
+	RetryDelay  time.Duration
+}
+
+// WorkerConfigFromEnv reads the pool size from the BOT_WORKERS and BOT_PREFETCH env variables, and the retries from BOT_MAX_ATTEMPTS and BOT_RETRY_DELAY
+// the prefetch defaults to twice the workers, so every worker has the next request at hand
+func WorkerConfigFromEnv() (WorkerConfig, error) {
+	workers, err := positiveIntFromEnv(workersEnv, defaultWorkers)
+	if err != nil {
+		return WorkerConfig{}, err
+	}
+
+	prefetch, err := positiveIntFromEnv(prefetchEnv, 2*workers)
+	if err != nil {
+		return WorkerConfig{}, err
+	}
+
+	attempts, err := positiveIntFromEnv(maxAttemptsEnv, defaultMaxAttempts)
+	if err != nil {
+		return WorkerConfig{}, err
+	}
+
+	delay := defaultRetryDelay
+	if value := os.Getenv(retryDelayEnv); value != "" {
+		delay, err = time.ParseDuration(value)
+		if err != nil || delay <= 0 {
+			return WorkerConfig{}, errors.New(fmt.Sprintf("invalid %s: %q", retryDelayEnv, value))
+		}
+	}
+
+	return WorkerConfig{Workers: workers, Prefetch: prefetch, MaxAttempts: attempts, RetryDelay: delay}, nil
+}
+
+func positiveIntFromEnv(name string, fallback int) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, errors.New(fmt.Sprintf("invalid %s: %q", name, value))
+	}
+
+	return n, nil
+}
+
+// serve processes the deliveries with the workers of the pool, it returns once the deliveries are closed and the workers are done
+func (s *StockService) serve(messages <-chan amqp.Delivery) {
+	workers := s.Pool.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for message := range messages {
+				s.handle(message)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+// handle answers a request and acks it once the reply is published
+// malformed requests are rejected to the dlq, the ones failing transiently are retried later,
+// and the ones whose reply cannot be published are requeued once before being dead-lettered
+func (s *StockService) handle(message amqp.Delivery) {
+	log.Printf("Stock received: %s\n", string(message.Body))
+
+	a, err := s.reply(message)
+	if err != nil {
+		log.Printf("%s, dead-lettering the request", err)
+		if err := message.Reject(false); err != nil {
+			log.Printf("error rejecting message: %s", err)
+		}
+		return
+	}
+
+	if a.Transient != nil && s.retryLater(message, a.Transient) {
+		return
+	}
+
+	if err := s.publish(a.ReplyTo, a.CorrelationID, a.Body); err != nil {
+		log.Printf("error publishing to the exchange: %s", err)
+		if err := message.Nack(false, !message.Redelivered); err != nil {
+			log.Printf("error requeuing message: %s", err)
+		}
+		return
+	}
+
+	if err := message.Ack(false); err != nil {
+		log.Printf("error acking message: %s", err)
+	}
+
+	log.Printf("Quote sent: %s\n", string(a.Body))
+}
+
+// retryLater sends a request that failed transiently to the retry queue, it returns false once the attempts are exhausted
+// so the last attempt is answered with the failure
+func (s *StockService) retryLater(message amqp.Delivery, cause error) bool {
+	attempt := attemptOf(message)
+	if attempt >= s.Pool.MaxAttempts {
+		return false
+	}
+
+	if err := s.retry(message, attempt+1); err != nil {
+		log.Printf("error retrying request: %s", err)
+		return false
+	}
+
+	if err := message.Ack(false); err != nil {
+		log.Printf("error acking message: %s", err)
+	}
+
+	log.Printf("Request failed with %q, retrying in %s (attempt %d of %d)", cause, s.Pool.RetryDelay, attempt+1, s.Pool.MaxAttempts)
+	return true
+}
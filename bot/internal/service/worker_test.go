@@ -0,0 +1,260 @@
+package service
+
+import (
+	"bot/internal/provider"
+	"encoding/json"
+	"errors"
+	"github.com/streadway/amqp"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeAcknowledger records how the deliveries were settled
+type fakeAcknowledger struct {
+	mu       sync.Mutex
+	acked    []uint64
+	requeued []uint64
+	dropped  []uint64
+}
+
+func (a *fakeAcknowledger) Ack(tag uint64, multiple bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.acked = append(a.acked, tag)
+	return nil
+}
+
+func (a *fakeAcknowledger) Nack(tag uint64, multiple bool, requeue bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if requeue {
+		a.requeued = append(a.requeued, tag)
+	} else {
+		a.dropped = append(a.dropped, tag)
+	}
+	return nil
+}
+
+func (a *fakeAcknowledger) Reject(tag uint64, requeue bool) error {
+	return a.Nack(tag, false, requeue)
+}
+
+func TestHandleSettlesTheDeliveries(t *testing.T) {
+	quotes := provider.NewFakeProvider(map[string]*provider.Quote{
+		"aapl.us": {Symbol: "AAPL.US", Close: 178.85},
+	})
+
+	s := NewStockService(nil, quotes, nil, nil, nil, WorkerConfig{})
+	failing := false
+	s.publish = func(replyTo string, correlationID string, body []byte) error {
+		if failing {
+			return errors.New("channel closed")
+		}
+		return nil
+	}
+
+	ack := &fakeAcknowledger{}
+	request := []byte(`{"stockCodes":["aapl.us"]}`)
+
+	s.handle(amqp.Delivery{Acknowledger: ack, DeliveryTag: 1, Body: request})
+	s.handle(amqp.Delivery{Acknowledger: ack, DeliveryTag: 2, Body: []byte("not json")})
+
+	failing = true
+	s.handle(amqp.Delivery{Acknowledger: ack, DeliveryTag: 3, Body: request})
+	s.handle(amqp.Delivery{Acknowledger: ack, DeliveryTag: 4, Body: request, Redelivered: true})
+
+	if len(ack.acked) != 1 || ack.acked[0] != 1 {
+		t.Errorf("expected only the answered request to be acked, got %v", ack.acked)
+	}
+	if len(ack.requeued) != 1 || ack.requeued[0] != 3 {
+		t.Errorf("expected the first failed reply to be requeued, got %v", ack.requeued)
+	}
+	if len(ack.dropped) != 2 || ack.dropped[0] != 2 || ack.dropped[1] != 4 {
+		t.Errorf("expected the malformed and redelivered requests to be dead-lettered, got %v", ack.dropped)
+	}
+}
+
+func TestHandleRetriesTransientFailures(t *testing.T) {
+	quotes := provider.NewFakeProvider(nil)
+	quotes.Err = provider.ErrUnavailable
+
+	s := NewStockService(nil, quotes, nil, nil, nil, WorkerConfig{MaxAttempts: 3})
+
+	var retried []int
+	var retries []amqp.Publishing
+	s.retry = func(message amqp.Delivery, attempt int) error {
+		retried = append(retried, attempt)
+		retries = append(retries, retryOf(message, attempt, time.Second))
+		return nil
+	}
+
+	var replies []string
+	s.publish = func(replyTo string, correlationID string, body []byte) error {
+		replies = append(replies, string(body))
+		return nil
+	}
+
+	ack := &fakeAcknowledger{}
+	request := []byte(`{"stockCodes":["aapl.us"]}`)
+
+	s.handle(amqp.Delivery{Acknowledger: ack, DeliveryTag: 1, Body: request})
+	s.handle(amqp.Delivery{Acknowledger: ack, DeliveryTag: 2, Body: request, Headers: amqp.Table{attemptsHeader: int32(3)}})
+	// a replayed request keeps its mark through the retries, so its last attempt is still answered in its room
+	s.handle(amqp.Delivery{Acknowledger: ack, DeliveryTag: 3, Body: request, Headers: amqp.Table{replayedHeader: true}})
+
+	if len(retried) != 2 || retried[0] != 2 || retried[1] != 2 {
+		t.Errorf("expected the first attempts to be retried as the second ones, got %v", retried)
+	}
+	if len(replies) != 1 {
+		t.Fatalf("expected only the last attempt to be answered, got %v", replies)
+	}
+	if len(ack.acked) != 3 {
+		t.Errorf("expected every delivery to be acked, got %v", ack.acked)
+	}
+
+	if _, ok := retries[0].Headers[replayedHeader]; ok {
+		t.Errorf("expected a request not to be marked as replayed, got %v", retries[0].Headers)
+	}
+	if retries[1].Headers[replayedHeader] != true || attemptOf(amqp.Delivery{Headers: retries[1].Headers}) != 2 {
+		t.Errorf("expected the retry to stay replayed and count the attempt, got %v", retries[1].Headers)
+	}
+}
+
+// failingHistory fails every history request with err
+type failingHistory struct {
+	err error
+}
+
+func (h failingHistory) GetHistory(code string, from time.Time, to time.Time) ([]*provider.Bar, error) {
+	return nil, h.err
+}
+
+func TestHandleRetriesTransientFailuresOfEveryRequest(t *testing.T) {
+	quotes := provider.NewFakeProvider(nil)
+	quotes.Err = provider.ErrRateLimited
+
+	tests := []struct {
+		name    string
+		key     string
+		history provider.HistoryProvider
+		retried bool
+	}{
+		{name: "history unavailable", key: historyKey, history: failingHistory{provider.ErrUnavailable}, retried: true},
+		{name: "chart unavailable", key: chartKey, history: failingHistory{provider.ErrUnavailable}, retried: true},
+		{name: "history not found", key: historyKey, history: failingHistory{provider.ErrStockNotFound}, retried: false},
+		{name: "fx rate limited", key: fxKey, retried: true},
+		{name: "crypto rate limited", key: cryptoKey, retried: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStockService(nil, quotes, tt.history, nil, nil, WorkerConfig{MaxAttempts: 3})
+
+			retried := false
+			s.retry = func(message amqp.Delivery, attempt int) error {
+				retried = true
+				return nil
+			}
+			s.publish = func(replyTo string, correlationID string, body []byte) error {
+				return nil
+			}
+
+			s.handle(amqp.Delivery{Acknowledger: &fakeAcknowledger{}, RoutingKey: tt.key, Body: []byte(`{"stockCodes":["eurusd"]}`)})
+
+			if retried != tt.retried {
+				t.Errorf("expected retried to be %t", tt.retried)
+			}
+		})
+	}
+}
+
+func TestHandleAnswersReplayedRequestsInTheirRoom(t *testing.T) {
+	s := NewStockService(nil, provider.NewFakeProvider(nil), nil, nil, nil, WorkerConfig{})
+
+	var replyTo, correlationID string
+	var qpl quotePayload
+	s.publish = func(key string, id string, body []byte) error {
+		replyTo, correlationID = key, id
+		return json.Unmarshal(body, &qpl)
+	}
+
+	s.handle(amqp.Delivery{
+		Acknowledger:  &fakeAcknowledger{},
+		CorrelationId: "42",
+		ReplyTo:       "messages.quote.gone",
+		Headers:       amqp.Table{replayedHeader: true},
+		Body:          []byte(`{"correlationID":"42","roomID":"general","stockCodes":["aapl.us"]}`),
+	})
+
+	if replyTo != digestKey || correlationID != "" || qpl.CorrelationID != "" || qpl.RoomID != "general" {
+		t.Errorf("expected an uncorrelated post to the room, got %q %q %+v", replyTo, correlationID, qpl)
+	}
+}
+
+func TestDeadLetterOf(t *testing.T) {
+	at := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	d := amqp.Delivery{
+		RoutingKey: stockKey,
+		Body:       []byte("not json"),
+		Headers: amqp.Table{"x-death": []interface{}{
+			amqp.Table{"reason": "rejected", "queue": queueName, "count": int64(1), "time": at},
+		}},
+	}
+
+	letter := deadLetterOf(d)
+	if letter.Reason != "rejected" || letter.Queue != queueName || letter.Count != 1 || !letter.Time.Equal(at) || letter.RoutingKey != stockKey {
+		t.Errorf("unexpected dead letter %+v", letter)
+	}
+}
+
+func TestServeProcessesConcurrently(t *testing.T) {
+	s := NewStockService(nil, provider.NewFakeProvider(nil), nil, nil, nil, WorkerConfig{Workers: 3})
+
+	// every reply blocks until the three requests are being processed at once
+	var started sync.WaitGroup
+	started.Add(3)
+	release := make(chan struct{})
+	go func() {
+		started.Wait()
+		close(release)
+	}()
+
+	s.publish = func(replyTo string, correlationID string, body []byte) error {
+		started.Done()
+		select {
+		case <-release:
+			return nil
+		case <-time.After(5 * time.Second):
+			return errors.New("the requests were not processed concurrently")
+		}
+	}
+
+	ack := &fakeAcknowledger{}
+	messages := make(chan amqp.Delivery, 3)
+	for tag := uint64(1); tag <= 3; tag++ {
+		messages <- amqp.Delivery{Acknowledger: ack, DeliveryTag: tag, Body: []byte(`{"stockCodes":["aapl.us"]}`)}
+	}
+	close(messages)
+
+	s.serve(messages)
+
+	if len(ack.acked) != 3 {
+		t.Errorf("expected the three requests to be acked, got %v, requeued %v", ack.acked, ack.requeued)
+	}
+}
+
+func TestWorkerConfigFromEnv(t *testing.T) {
+	t.Setenv(workersEnv, "")
+	t.Setenv(prefetchEnv, "")
+
+	cfg, err := WorkerConfigFromEnv()
+	if err != nil || cfg.Workers != defaultWorkers || cfg.Prefetch != 2*defaultWorkers {
+		t.Errorf("unexpected default config %+v, %v", cfg, err)
+	}
+
+	t.Setenv(workersEnv, "0")
+	if _, err := WorkerConfigFromEnv(); err == nil {
+		t.Error("expected an error for a pool without workers")
+	}
+}
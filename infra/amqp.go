@@ -0,0 +1,487 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/streadway/amqp"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Channel is the part of an amqp channel used by the manager and the topologies, *amqp.Channel implements it
+type Channel interface {
+	ExchangeDeclare(name string, kind string, durable bool, autoDelete bool, internal bool, noWait bool, args amqp.Table) error
+	QueueDeclare(name string, durable bool, autoDelete bool, exclusive bool, noWait bool, args amqp.Table) (amqp.Queue, error)
+	QueueBind(name string, key string, exchange string, noWait bool, args amqp.Table) error
+	QueueDelete(name string, ifUnused bool, ifEmpty bool, noWait bool) (int, error)
+	Qos(prefetchCount int, prefetchSize int, global bool) error
+	Consume(queue string, consumer string, autoAck bool, exclusive bool, noLocal bool, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error)
+	Cancel(consumer string, noWait bool) error
+	Publish(exchange string, key string, mandatory bool, immediate bool, msg amqp.Publishing) error
+	Get(queue string, autoAck bool) (amqp.Delivery, bool, error)
+	Nack(tag uint64, multiple bool, requeue bool) error
+	Close() error
+}
+
+// Connection is the part of an amqp connection used by the manager
+type Connection interface {
+	Channel() (Channel, error)
+	NotifyClose(receiver chan *amqp.Error) chan *amqp.Error
+	Close() error
+}
+
+// Dialer opens a connection to rabbitmq
+type Dialer func(url string) (Connection, error)
+
+// Topology declares the exchanges, queues and bindings a connection needs
+// it runs again on a fresh channel after every reconnection
+type Topology func(ch Channel) error
+
+// ConsumeOptions describes a consumer, Setup declares the queue and its bindings before consuming from it
+// closing Done cancels the consumer while keeping its channel open to acknowledge the deliveries in flight
+type ConsumeOptions struct {
+	Queue     string
+	Prefetch  int
+	AutoAck   bool
+	Exclusive bool
+	Setup     Topology
+	Done      <-chan struct{}
+}
+
+var ErrClosed = errors.New("amqp connection manager is closed")
+
+const (
+	channelPoolSize = 8
+	minBackoff      = 500 * time.Millisecond
+	maxBackoff      = 30 * time.Second
+)
+
+type pooledChannel struct {
+	ch   Channel
+	conn Connection
+}
+
+// amqpConnection adapts an *amqp.Connection to the Connection interface
+type amqpConnection struct {
+	*amqp.Connection
+}
+
+func (c *amqpConnection) Channel() (Channel, error) {
+	ch, err := c.Connection.Channel()
+	if err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// dialAMQP dials rabbitmq with the amqp library
+func dialAMQP(url string) (Connection, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+	return &amqpConnection{conn}, nil
+}
+
+// ConnectionManager keeps a single long-lived connection to rabbitmq, reconnecting with backoff when it is lost
+// Topologies and consumers are registered once and restored transparently after every reconnection
+type ConnectionManager struct {
+	url  string
+	dial Dialer
+
+	mu         sync.Mutex
+	conn       Connection
+	ready      chan struct{}
+	topologies []Topology
+
+	channels chan *pooledChannel
+	closed   chan struct{}
+	once     sync.Once
+}
+
+// URLFromEnv builds the rabbitmq url from the RABBITMQ_* env variables
+func URLFromEnv() string {
+	user, password, host := os.Getenv("RABBITMQ_USERNAME"), os.Getenv("RABBITMQ_PASSWORD"), os.Getenv("RABBITMQ_HOST")
+
+	return fmt.Sprintf("amqp://%s:%s@%s/", user, password, host)
+}
+
+// NewConnectionManager builds a connection manager for the given url, Connect must be called before using it
+func NewConnectionManager(url string) *ConnectionManager {
+	return &ConnectionManager{
+		url:      url,
+		dial:     dialAMQP,
+		ready:    make(chan struct{}),
+		channels: make(chan *pooledChannel, channelPoolSize),
+		closed:   make(chan struct{}),
+	}
+}
+
+// Connect dials rabbitmq and starts watching the connection to reconnect when it is closed
+func (m *ConnectionManager) Connect() error {
+	conn, err := m.dial(m.url)
+	if err != nil {
+		return errors.New(fmt.Sprintf("error dialing amqp: %s", err))
+	}
+
+	m.setConnection(conn)
+	go m.watch(conn)
+
+	return nil
+}
+
+// Declare registers a topology and runs it, it is declared again after every reconnection
+func (m *ConnectionManager) Declare(t Topology) error {
+	m.mu.Lock()
+	m.topologies = append(m.topologies, t)
+	m.mu.Unlock()
+
+	pc, err := m.channel(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if err := t(pc.ch); err != nil {
+		pc.ch.Close()
+		return err
+	}
+
+	m.release(pc)
+	return nil
+}
+
+// Publish publishes a message using a pooled channel, waiting for the connection if it is being restored
+func (m *ConnectionManager) Publish(exchange string, key string, msg amqp.Publishing) error {
+	return m.PublishContext(context.Background(), exchange, key, msg)
+}
+
+// PublishContext publishes a message like Publish, but stops waiting for the connection once ctx is done
+func (m *ConnectionManager) PublishContext(ctx context.Context, exchange string, key string, msg amqp.Publishing) error {
+	pc, err := m.channel(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := pc.ch.Publish(exchange, key, false, false, msg); err != nil {
+		pc.ch.Close()
+		return errors.New(fmt.Sprintf("error publishing message: %s", err))
+	}
+
+	m.release(pc)
+	return nil
+}
+
+// WithChannel runs fn on a dedicated channel and closes it afterwards, for the operations that must share a channel, such as getting and acking messages
+func (m *ConnectionManager) WithChannel(fn func(ch Channel) error) error {
+	conn, err := m.connection()
+	if err != nil {
+		return err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return errors.New(fmt.Sprintf("error opening amqp channel: %s", err))
+	}
+	defer ch.Close()
+
+	return fn(ch)
+}
+
+// Consume returns the deliveries of a queue on a dedicated channel
+// the returned channel survives reconnections and is only closed when the manager is closed or opts.Done is closed
+// deliveries received before a reconnection can no longer be acknowledged after it
+func (m *ConnectionManager) Consume(opts ConsumeOptions) (<-chan amqp.Delivery, error) {
+	ch, deliveries, err := m.consume(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan amqp.Delivery)
+
+	go func() {
+		defer close(out)
+
+		backoff := minBackoff
+		for {
+			if !m.forward(deliveries, out, opts.Done) {
+				select {
+				case <-opts.Done:
+					// unread deliveries stay unacked and are requeued once the channel is closed
+					if err := ch.Cancel(opts.Queue, false); err != nil {
+						log.Printf("error cancelling consumer on queue %s: %s", opts.Queue, err)
+					}
+				default:
+				}
+				return
+			}
+
+			// the channel was closed, resume consuming once the connection is restored
+			for {
+				if _, err := m.connection(); err != nil {
+					return
+				}
+
+				ch, deliveries, err = m.consume(opts)
+				if err == nil {
+					log.Printf("Consumer resumed on queue %s", opts.Queue)
+					backoff = minBackoff
+					break
+				}
+
+				log.Printf("error resuming consumer on queue %s: %s", opts.Queue, err)
+				if !m.sleep(backoff) {
+					return
+				}
+				backoff = nextBackoff(backoff)
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// Close closes the connection and stops reconnecting
+func (m *ConnectionManager) Close() {
+	m.once.Do(func() {
+		close(m.closed)
+
+		m.mu.Lock()
+		conn := m.conn
+		m.mu.Unlock()
+
+		if conn != nil {
+			conn.Close()
+		}
+	})
+}
+
+// forward passes deliveries on until the amqp channel is closed, it returns false when the consumer must stop
+func (m *ConnectionManager) forward(deliveries <-chan amqp.Delivery, out chan<- amqp.Delivery, done <-chan struct{}) bool {
+	for {
+		select {
+		case d, ok := <-deliveries:
+			if !ok {
+				return true
+			}
+
+			select {
+			case out <- d:
+			case <-m.closed:
+				return false
+			case <-done:
+				return false
+			}
+		case <-m.closed:
+			return false
+		case <-done:
+			return false
+		}
+	}
+}
+
+// consume opens a channel for the consumer, declares its queue and starts consuming from it
+// the consumer is tagged with its queue name, each consumer has a channel of its own
+func (m *ConnectionManager) consume(opts ConsumeOptions) (Channel, <-chan amqp.Delivery, error) {
+	conn, err := m.connection()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, nil, errors.New(fmt.Sprintf("error opening amqp channel: %s", err))
+	}
+
+	if opts.Prefetch > 0 {
+		if err := ch.Qos(opts.Prefetch, 0, false); err != nil {
+			ch.Close()
+			return nil, nil, errors.New(fmt.Sprintf("error setting channel qos: %s", err))
+		}
+	}
+
+	if opts.Setup != nil {
+		if err := opts.Setup(ch); err != nil {
+			ch.Close()
+			return nil, nil, err
+		}
+	}
+
+	deliveries, err := ch.Consume(opts.Queue, opts.Queue, opts.AutoAck, opts.Exclusive, false, false, nil)
+	if err != nil {
+		ch.Close()
+		return nil, nil, errors.New(fmt.Sprintf("error consuming queued messages: %s", err))
+	}
+
+	return ch, deliveries, nil
+}
+
+// watch waits for the connection to be closed and reconnects with exponential backoff
+func (m *ConnectionManager) watch(conn Connection) {
+	for {
+		select {
+		case err := <-conn.NotifyClose(make(chan *amqp.Error, 1)):
+			log.Printf("amqp connection closed: %v", err)
+		case <-m.closed:
+			return
+		}
+
+		select {
+		case <-m.closed:
+			return
+		default:
+		}
+
+		m.setConnection(nil)
+
+		backoff := minBackoff
+		for {
+			if !m.sleep(backoff) {
+				return
+			}
+
+			next, err := m.dial(m.url)
+			if err != nil {
+				log.Printf("error reconnecting to amqp, retrying in %s: %s", nextBackoff(backoff), err)
+				backoff = nextBackoff(backoff)
+				continue
+			}
+
+			if err := m.redeclare(next); err != nil {
+				log.Printf("error restoring amqp topology: %s", err)
+				next.Close()
+				backoff = nextBackoff(backoff)
+				continue
+			}
+
+			log.Println("amqp connection restored")
+			conn = next
+			m.setConnection(conn)
+			break
+		}
+	}
+}
+
+// redeclare runs all the registered topologies on a new connection
+func (m *ConnectionManager) redeclare(conn Connection) error {
+	m.mu.Lock()
+	topologies := append([]Topology(nil), m.topologies...)
+	m.mu.Unlock()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return errors.New(fmt.Sprintf("error opening amqp channel: %s", err))
+	}
+	defer ch.Close()
+
+	for _, t := range topologies {
+		if err := t(ch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setConnection swaps the current connection, a nil connection makes callers wait until a new one is set
+func (m *ConnectionManager) setConnection(conn Connection) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.conn = conn
+
+	if conn == nil {
+		select {
+		case <-m.ready:
+			m.ready = make(chan struct{})
+		default:
+		}
+		return
+	}
+
+	select {
+	case <-m.ready:
+	default:
+		close(m.ready)
+	}
+}
+
+// connection returns the current connection, waiting for it while it is being restored
+func (m *ConnectionManager) connection() (Connection, error) {
+	return m.connectionContext(context.Background())
+}
+
+// connectionContext returns the current connection, waiting for it while it is being restored until ctx is done
+func (m *ConnectionManager) connectionContext(ctx context.Context) (Connection, error) {
+	for {
+		m.mu.Lock()
+		conn, ready := m.conn, m.ready
+		m.mu.Unlock()
+
+		if conn != nil {
+			return conn, nil
+		}
+
+		select {
+		case <-ready:
+		case <-m.closed:
+			return nil, ErrClosed
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// channel takes a channel from the pool, or opens a new one if the pool is empty
+func (m *ConnectionManager) channel(ctx context.Context) (*pooledChannel, error) {
+	conn, err := m.connectionContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		select {
+		case pc := <-m.channels:
+			if pc.conn == conn {
+				return pc, nil
+			}
+			// the channel belongs to a lost connection
+			pc.ch.Close()
+		default:
+			ch, err := conn.Channel()
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("error opening amqp channel: %s", err))
+			}
+			return &pooledChannel{ch: ch, conn: conn}, nil
+		}
+	}
+}
+
+// release returns a channel to the pool, or closes it if the pool is full
+func (m *ConnectionManager) release(pc *pooledChannel) {
+	select {
+	case m.channels <- pc:
+	default:
+		pc.ch.Close()
+	}
+}
+
+// sleep waits for the given duration, returning false if the manager is closed meanwhile
+func (m *ConnectionManager) sleep(d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-m.closed:
+		return false
+	}
+}
+
+func nextBackoff(d time.Duration) time.Duration {
+	d *= 2
+	if d > maxBackoff {
+		return maxBackoff
+	}
+	return d
+}
@@ -0,0 +1,5 @@
+module infra
+
+go 1.20
+
+require github.com/streadway/amqp v1.1.0
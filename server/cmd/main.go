@@ -7,6 +7,7 @@ import (
 	"github.com/joho/godotenv"
 	"log"
 	"net/http"
+	"os"
 	"server/db"
 	"server/internal/handler"
 	"server/internal/infra"
@@ -21,30 +22,62 @@ func main() {
 
 	conn, err := db.NewDatabase()
 	if err != nil {
-		fmt.Errorf("error getting db connection: %s", err.Error())
+		log.Fatalf("error getting db connection: %s", err)
+	}
+
+	jwtSecret := os.Getenv("JWT_SECRET")
+	if jwtSecret == "" {
+		log.Fatal("JWT_SECRET is not set")
 	}
 
 	router := mux.NewRouter()
 
+	tokenService := service.NewTokenService(jwtSecret)
+
 	userRepo := repo.NewUserRepository(conn.GetDB())
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, tokenService)
 	userHandler := handler.NewUserHandler(userService)
 	userHandler.Attach(router)
 
+	// routes attached from here on require a valid session token
+	protected := router.NewRoute().Subrouter()
+	protected.Use(handler.AuthMiddleware(tokenService))
+
+	roomRepo := repo.NewRoomRepository(conn.GetDB())
+	roomService := service.NewRoomService(roomRepo)
+	roomHandler := handler.NewRoomHandler(roomService)
+	roomHandler.Attach(protected)
+
 	amqpClient := infra.NewAMQPClient()
+	if err := amqpClient.SetupAMQExchange(); err != nil {
+		log.Fatalf("error setting up the amq connection and exchange: %s", err)
+	}
+
+	watchlistService := service.NewWatchlistService(repo.NewWatchlistRepository(conn.GetDB()))
+	watchlistHandler := handler.NewWatchlistHandler(watchlistService)
+	watchlistHandler.Attach(protected)
 
 	postRepo := repo.NewPostRepository(conn.GetDB())
 	postService := service.NewPostService(postRepo)
-	commandService := service.NewCommandService(postRepo, amqpClient)
-	postHandler := handler.NewPostHandler(postService, commandService)
-	postHandler.Attach(router)
+	alertRepo := repo.NewAlertRepository(conn.GetDB())
+	commandService := service.NewCommandService(postRepo, alertRepo, watchlistService, amqpClient)
+	hub := handler.NewHub(amqpClient)
+	if err := hub.Connect(); err != nil {
+		log.Fatalf("error connecting the hub to the event bus: %s", err)
+	}
+	postHandler := handler.NewPostHandler(postService, commandService, roomService, hub)
+	postHandler.Attach(protected)
+	symbolHandler := handler.NewSymbolHandler(commandService)
+	symbolHandler.Attach(protected)
 
-	// Separate goroutine for listening to new messages
-	go postHandler.WriteMessages()
+	// Separate goroutines for listening to new messages and quotes
+	go hub.Run()
+	go postHandler.BroadcastCommands()
+	go postHandler.BroadcastAlerts()
 
 	allowedOrigins := handlers.AllowedOrigins([]string{"*"})
-	allowedMethods := handlers.AllowedMethods([]string{"GET", "POST"})
-	allowedHeaders := handlers.AllowedHeaders([]string{"Content-Type"})
+	allowedMethods := handlers.AllowedMethods([]string{"GET", "POST", "DELETE"})
+	allowedHeaders := handlers.AllowedHeaders([]string{"Content-Type", "Authorization"})
 
 	err = http.ListenAndServe(":5000", handlers.CORS(allowedOrigins, allowedMethods, allowedHeaders)(router))
 	if err != nil {
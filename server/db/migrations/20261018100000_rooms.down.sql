@@ -0,0 +1,3 @@
+ALTER TABLE posts DROP COLUMN IF EXISTS room_id;
+DROP TABLE IF EXISTS room_members;
+DROP TABLE IF EXISTS rooms;
\ No newline at end of file
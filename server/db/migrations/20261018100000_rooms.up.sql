@@ -0,0 +1,22 @@
+CREATE TABLE rooms
+(
+    id         uuid primary key default gen_random_uuid(),
+    name       text not null unique,
+    created_by uuid references users(id),
+    created_at timestamp not null default now()
+);
+
+CREATE TABLE room_members
+(
+    room_id   uuid references rooms(id) on delete cascade,
+    user_id   uuid references users(id) on delete cascade,
+    joined_at timestamp not null default now(),
+    primary key (room_id, user_id)
+);
+
+INSERT INTO rooms (id, name) VALUES ('6b1d0f5e-2a1c-4f0e-9d7a-3c5e8b2f4a10', 'general');
+INSERT INTO room_members (room_id, user_id) SELECT '6b1d0f5e-2a1c-4f0e-9d7a-3c5e8b2f4a10', id FROM users;
+
+ALTER TABLE posts ADD COLUMN room_id uuid references rooms(id) on delete cascade;
+UPDATE posts SET room_id = '6b1d0f5e-2a1c-4f0e-9d7a-3c5e8b2f4a10';
+ALTER TABLE posts ALTER COLUMN room_id SET NOT NULL;
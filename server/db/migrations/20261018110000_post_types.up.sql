@@ -0,0 +1 @@
+ALTER TABLE posts ADD COLUMN type text not null default 'chat';
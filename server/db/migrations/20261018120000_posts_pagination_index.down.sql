@@ -0,0 +1 @@
+DROP INDEX IF EXISTS posts_room_timestamp_id_idx;
\ No newline at end of file
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS posts_room_timestamp_id_idx ON posts (room_id, timestamp DESC, id DESC);
@@ -0,0 +1,14 @@
+CREATE TABLE alerts
+(
+    id           bigserial primary key,
+    user_id      uuid not null references users(id) on delete cascade,
+    room_id      uuid not null references rooms(id) on delete cascade,
+    symbol       text not null,
+    operator     text not null check (operator in ('>', '<')),
+    threshold    numeric not null,
+    created_at   timestamp not null default now(),
+    triggered_at timestamp
+);
+
+CREATE INDEX alerts_active_idx ON alerts (symbol) WHERE triggered_at IS NULL;
+CREATE INDEX alerts_user_room_idx ON alerts (user_id, room_id);
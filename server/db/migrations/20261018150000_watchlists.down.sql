@@ -0,0 +1 @@
+DROP TABLE IF EXISTS watchlists;
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE TABLE watchlists
+(
+    user_id    uuid not null references users(id) on delete cascade,
+    symbol     text not null,
+    created_at timestamp not null default now(),
+    primary key (user_id, symbol)
+);
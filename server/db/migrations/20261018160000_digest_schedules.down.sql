@@ -0,0 +1 @@
+DROP TABLE IF EXISTS digest_schedules;
\ No newline at end of file
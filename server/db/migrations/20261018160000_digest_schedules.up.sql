@@ -0,0 +1,16 @@
+CREATE TABLE digest_schedules
+(
+    id          bigserial primary key,
+    room_id     uuid not null references rooms(id) on delete cascade,
+    name        text not null,
+    cron        text not null,
+    timezone    text not null default 'UTC',
+    symbols     text[] not null,
+    enabled     boolean not null default true,
+    last_run_at timestamp,
+    created_at  timestamp not null default now()
+);
+
+INSERT INTO digest_schedules (room_id, name, cron, timezone, symbols)
+VALUES ('6b1d0f5e-2a1c-4f0e-9d7a-3c5e8b2f4a10', 'Market open', '30 9 * * 1-5', 'America/New_York', '{aapl.us,msft.us,googl.us,amzn.us,tsla.us}'),
+       ('6b1d0f5e-2a1c-4f0e-9d7a-3c5e8b2f4a10', 'Market close', '0 16 * * 1-5', 'America/New_York', '{aapl.us,msft.us,googl.us,amzn.us,tsla.us}');
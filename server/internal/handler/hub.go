@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"context"
+	"github.com/gorilla/websocket"
+	"github.com/streadway/amqp"
+	"log"
+	"server/internal/infra"
+	"server/internal/model"
+	"sync"
+	"time"
+)
+
+const (
+	// writeWait is the time allowed to write a message to the peer
+	writeWait = 10 * time.Second
+	// pongWait is the time allowed to read the next pong from the peer
+	pongWait = 60 * time.Second
+	// pingPeriod must be shorter than pongWait, so the peer answers before the read deadline
+	pingPeriod = pongWait * 9 / 10
+	// maxMessageSize is the largest frame accepted from the peer
+	maxMessageSize = 4096
+	// sendBufferSize is the number of events queued for a client before it is evicted as too slow
+	sendBufferSize = 256
+	// publishTimeout bounds the wait for the bus, so the events keep flowing locally while rabbitmq is unreachable
+	publishTimeout = time.Second
+)
+
+// client is a websocket connection subscribed to a room
+// all the writes go through its send queue, and its write pump is the only writer of the connection
+type client struct {
+	hub    *Hub
+	conn   *websocket.Conn
+	user   *model.User
+	roomID string
+
+	send chan []byte
+	// done is closed by the hub when the client is unregistered or evicted
+	done chan struct{}
+	once sync.Once
+}
+
+// Hub keeps the clients connected to every room and fans the broadcast events out to them
+// the events are published to the event bus shared by all the server instances, and the hub delivers
+// the events received from the bus to its own clients, so a room can span several instances
+// the clients map is only accessed by the Run goroutine, registrations go through channels
+type Hub struct {
+	AMQPClient infra.AMQPClient
+
+	clients    map[*client]bool
+	register   chan *client
+	unregister chan *client
+	// broadcast receives the events produced by this instance, deliver the events received from the bus
+	broadcast chan *model.Broadcast
+	deliver   chan *model.Broadcast
+}
+
+// NewHub builds a hub and injects its dependencies, Connect and Run must be called to start serving its clients
+func NewHub(amqpClient infra.AMQPClient) *Hub {
+	return &Hub{
+		AMQPClient: amqpClient,
+		clients:    make(map[*client]bool),
+		register:   make(chan *client),
+		unregister: make(chan *client),
+		broadcast:  make(chan *model.Broadcast, sendBufferSize),
+		deliver:    make(chan *model.Broadcast, sendBufferSize),
+	}
+}
+
+// Connect subscribes the hub to the event bus and starts publishing the events produced by this instance to it
+func (h *Hub) Connect() error {
+	events, err := h.AMQPClient.ConsumeEvents()
+	if err != nil {
+		return err
+	}
+
+	go h.publish()
+	go h.consume(events)
+
+	return nil
+}
+
+// Run registers and unregisters the clients and sends the events received from the bus to the clients of their room
+// a client whose send queue is full is evicted instead of blocking the rest of the room
+func (h *Hub) Run() {
+	for {
+		select {
+		case c := <-h.register:
+			h.clients[c] = true
+		case c := <-h.unregister:
+			h.remove(c)
+		case msg := <-h.deliver:
+			for c := range h.clients {
+				if c.roomID != msg.RoomID {
+					continue
+				}
+
+				select {
+				case c.send <- msg.Payload:
+				default:
+					log.Printf("evicting slow client %s from room %s", c.user.Username, c.roomID)
+					h.remove(c)
+				}
+			}
+		}
+	}
+}
+
+// publish sends the events produced by this instance to the bus
+// if the bus rejects an event or is not reachable within publishTimeout, it is still delivered to the clients of this instance
+func (h *Hub) publish() {
+	for msg := range h.broadcast {
+		ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+		err := h.AMQPClient.PublishEvent(ctx, msg.RoomID, msg.Payload)
+		cancel()
+
+		if err != nil {
+			log.Printf("error publishing event: %s", err)
+			h.deliver <- msg
+		}
+	}
+}
+
+// consume hands the events received from the bus to the Run goroutine
+func (h *Hub) consume(events <-chan amqp.Delivery) {
+	for event := range events {
+		roomID, ok := event.Headers[infra.RoomHeader].(string)
+		if !ok {
+			log.Printf("error consuming event: missing %s header", infra.RoomHeader)
+			continue
+		}
+
+		h.deliver <- &model.Broadcast{
+			RoomID:  roomID,
+			Payload: event.Body,
+		}
+	}
+}
+
+// newClient builds a client of the hub for the connection
+func (h *Hub) newClient(conn *websocket.Conn, user *model.User, roomID string) *client {
+	return &client{
+		hub:    h,
+		conn:   conn,
+		user:   user,
+		roomID: roomID,
+		send:   make(chan []byte, sendBufferSize),
+		done:   make(chan struct{}),
+	}
+}
+
+// remove unregisters a client and stops its write pump
+func (h *Hub) remove(c *client) {
+	if _, ok := h.clients[c]; !ok {
+		return
+	}
+
+	delete(h.clients, c)
+	c.close()
+}
+
+// close stops the write pump of the client, it is safe to call it more than once
+func (c *client) close() {
+	c.once.Do(func() {
+		close(c.done)
+	})
+}
+
+// enqueue queues a payload only for this client, the client is evicted if its queue is full
+func (c *client) enqueue(payload []byte) {
+	select {
+	case c.send <- payload:
+	case <-c.done:
+	default:
+		log.Printf("evicting slow client %s from room %s", c.user.Username, c.roomID)
+		c.hub.unregister <- c
+	}
+}
+
+// writePump writes the queued payloads to the connection and pings the peer to keep it alive
+// it closes the connection when the client is unregistered, which makes the read loop return
+func (c *client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case payload := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				log.Printf("error writing to client: %s", err)
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				log.Printf("error pinging client: %s", err)
+				return
+			}
+		case <-c.done:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+			return
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"server/internal/infra"
+	mock_infra "server/internal/infra/mocks"
+	"server/internal/model"
+	"testing"
+	"time"
+)
+
+const roomID = "6b1d0f5e-2a1c-4f0e-9d7a-3c5e8b2f4a10"
+
+func TestHubBroadcastsOnlyToTheRoom(t *testing.T) {
+	hub := newLoopbackHub(t)
+
+	member := hub.newClient(nil, &model.User{Username: "Alice"}, roomID)
+	stranger := hub.newClient(nil, &model.User{Username: "Bob"}, "f1c21d1d-3411-4bfd-a99f-8fc52dc65bb5")
+	hub.register <- member
+	hub.register <- stranger
+
+	hub.broadcast <- &model.Broadcast{RoomID: roomID, Payload: []byte("Hello!")}
+
+	select {
+	case got := <-member.send:
+		assert.Equal(t, "Hello!", string(got))
+	case <-time.After(time.Second):
+		t.Fatal("Expected the member to receive the broadcast")
+	}
+
+	assert.Empty(t, stranger.send, "Expected clients of other rooms not to receive the broadcast")
+}
+
+func TestHubEvictsSlowClients(t *testing.T) {
+	hub := newLoopbackHub(t)
+
+	slow := hub.newClient(nil, &model.User{Username: "Alice"}, roomID)
+	hub.register <- slow
+
+	// nobody drains the send queue of the client, the broadcast past its size evicts it
+	for i := 0; i <= sendBufferSize; i++ {
+		hub.broadcast <- &model.Broadcast{RoomID: roomID, Payload: []byte("Hello!")}
+	}
+
+	select {
+	case <-slow.done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the slow client to be evicted")
+	}
+}
+
+func TestHubDeliversEventsFromOtherInstances(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	events := make(chan amqp.Delivery)
+
+	mockAMQP := mock_infra.NewMockAMQPClient(ctrl)
+	mockAMQP.EXPECT().ConsumeEvents().Return(events, nil)
+
+	hub := NewHub(mockAMQP)
+	if err := hub.Connect(); err != nil {
+		t.Fatalf("Failed to connect the hub: %s", err)
+	}
+	go hub.Run()
+
+	member := hub.newClient(nil, &model.User{Username: "Alice"}, roomID)
+	hub.register <- member
+
+	// an event published by another instance, not produced by this hub
+	events <- amqp.Delivery{
+		Headers: amqp.Table{infra.RoomHeader: roomID},
+		Body:    []byte("Hello from another instance!"),
+	}
+
+	select {
+	case got := <-member.send:
+		assert.Equal(t, "Hello from another instance!", string(got))
+	case <-time.After(time.Second):
+		t.Fatal("Expected the member to receive the event of the other instance")
+	}
+}
+
+func TestHubDeliversLocallyWhenTheBusIsUnreachable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAMQP := mock_infra.NewMockAMQPClient(ctrl)
+	mockAMQP.EXPECT().ConsumeEvents().Return(make(chan amqp.Delivery), nil)
+	mockAMQP.EXPECT().PublishEvent(gomock.Any(), roomID, gomock.Any()).DoAndReturn(func(ctx context.Context, roomID string, payload []byte) error {
+		// rabbitmq is reconnecting, the publish waits until the hub gives up
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	hub := NewHub(mockAMQP)
+	if err := hub.Connect(); err != nil {
+		t.Fatalf("Failed to connect the hub: %s", err)
+	}
+	go hub.Run()
+
+	member := hub.newClient(nil, &model.User{Username: "Alice"}, roomID)
+	hub.register <- member
+
+	hub.broadcast <- &model.Broadcast{RoomID: roomID, Payload: []byte("Hello!")}
+
+	select {
+	case got := <-member.send:
+		assert.Equal(t, "Hello!", string(got))
+	case <-time.After(publishTimeout + time.Second):
+		t.Fatal("Expected the event to be delivered locally once the publish timed out")
+	}
+}
+
+// newLoopbackHub builds a running hub whose events published to the bus are consumed back by itself
+func newLoopbackHub(t *testing.T) *Hub {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	events := make(chan amqp.Delivery, 2*sendBufferSize)
+
+	mockAMQP := mock_infra.NewMockAMQPClient(ctrl)
+	mockAMQP.EXPECT().ConsumeEvents().Return(events, nil)
+	mockAMQP.EXPECT().PublishEvent(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, roomID string, payload []byte) error {
+		events <- amqp.Delivery{
+			Headers: amqp.Table{infra.RoomHeader: roomID},
+			Body:    payload,
+		}
+		return nil
+	}).AnyTimes()
+
+	hub := NewHub(mockAMQP)
+	if err := hub.Connect(); err != nil {
+		t.Fatalf("Failed to connect the hub: %s", err)
+	}
+	go hub.Run()
+
+	return hub
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"context"
+	"github.com/gorilla/mux"
+	"log"
+	"net/http"
+	"server/internal/model"
+	"server/internal/service"
+	"strings"
+)
+
+type contextKey string
+
+const (
+	userContextKey = contextKey("user")
+	bearerPrefix   = "Bearer "
+	tokenParam     = "token"
+)
+
+// AuthMiddleware validates the session token of every request and stores the authenticated user in its context
+// Browsers cannot set headers on websocket requests, so the token is also accepted as the <token> query param
+func AuthMiddleware(ts service.TokenService) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token := r.URL.Query().Get(tokenParam)
+			if header := r.Header.Get("Authorization"); strings.HasPrefix(header, bearerPrefix) {
+				token = strings.TrimPrefix(header, bearerPrefix)
+			}
+
+			if token == "" {
+				http.Error(w, "Missing session token", http.StatusUnauthorized)
+				return
+			}
+
+			user, err := ts.ValidateToken(token)
+			if err != nil {
+				log.Printf("error validating session token: %s", err)
+				http.Error(w, "Invalid session token", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userContextKey, user)))
+		})
+	}
+}
+
+// userFromContext returns the user authenticated by the AuthMiddleware
+func userFromContext(ctx context.Context) *model.User {
+	user, _ := ctx.Value(userContextKey).(*model.User)
+	return user
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"server/internal/model"
+	mock_service "server/internal/service/mocks"
+	"testing"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := &model.User{
+		ID:       uuid.New(),
+		Username: "Bob",
+	}
+
+	mockTokens := mock_service.NewMockTokenService(ctrl)
+	mockTokens.EXPECT().ValidateToken("valid-token").Return(user, nil).Times(2)
+	mockTokens.EXPECT().ValidateToken("invalid-token").Return(nil, errors.New("token is expired"))
+
+	var got *model.User
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = userFromContext(r.Context())
+	})
+	handler := AuthMiddleware(mockTokens)(next)
+
+	tests := []struct {
+		name   string
+		header string
+		query  string
+		code   int
+		user   *model.User
+	}{
+		{name: "missing token", code: http.StatusUnauthorized},
+		{name: "invalid token", header: "Bearer invalid-token", code: http.StatusUnauthorized},
+		{name: "bearer header", header: "Bearer valid-token", code: http.StatusOK, user: user},
+		{name: "query param", query: "?token=valid-token", code: http.StatusOK, user: user},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+
+			req := httptest.NewRequest("GET", "/ws"+tt.query, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.code, rec.Code)
+			assert.Equal(t, tt.user, got)
+		})
+	}
+}
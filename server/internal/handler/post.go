@@ -3,39 +3,109 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 	"log"
 	"net/http"
 	"server/internal/model"
 	"server/internal/service"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type PostHandler struct {
 	Service        service.PostService
 	CommandService service.CommmandService
+	RoomService    service.RoomService
+	Hub            *Hub
 }
 
-var (
-	broadcast = make(chan []byte)
-	clients   = make(map[*websocket.Conn]bool)
-)
+const roomParam = "room"
 
 // NewPostHandler builds a handler and injects its dependencies
-func NewPostHandler(s service.PostService, cs service.CommmandService) *PostHandler {
+func NewPostHandler(s service.PostService, cs service.CommmandService, rs service.RoomService, hub *Hub) *PostHandler {
 	return &PostHandler{
 		Service:        s,
 		CommandService: cs,
+		RoomService:    rs,
+		Hub:            hub,
 	}
 }
 
-// Attach attaches the web socket endpoints to the router
+// Attach attaches the web socket and posts endpoints to the router, which must be protected by the AuthMiddleware
 func (h *PostHandler) Attach(r *mux.Router) {
 	r.HandleFunc("/ws", h.HandleWebSocketConnection)
+	r.HandleFunc("/rooms/{id}/posts", h.HandleGetPosts).Methods("GET", "OPTIONS")
+}
+
+// HandleGetPosts returns a page of posts of a room, paginated with the <before> or <after> cursors and <limit>
+func (h *PostHandler) HandleGetPosts(w http.ResponseWriter, r *http.Request) {
+	roomID := mux.Vars(r)["id"]
+	query := r.URL.Query()
+
+	limit := 0
+	if l := query.Get("limit"); l != "" {
+		var err error
+		if limit, err = strconv.Atoi(l); err != nil || limit < 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	member, err := h.RoomService.IsMember(r.Context(), roomID, userFromContext(r.Context()))
+	if err != nil {
+		log.Printf("error checking room membership: %s", err)
+		http.Error(w, "Failed to check room membership", http.StatusInternalServerError)
+		return
+	}
+	if !member {
+		http.Error(w, "Join the room before reading its posts", http.StatusForbidden)
+		return
+	}
+
+	page, err := h.Service.GetPosts(r.Context(), roomID, query.Get("before"), query.Get("after"), limit)
+	if errors.Is(err, service.ErrInvalidCursor) || errors.Is(err, service.ErrBothCursors) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("error getting posts: %s", err)
+		http.Error(w, "Failed to get posts", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
 }
 
-// HandleWebSocketConnection establishes a web socket connection and reads messages coming through it
+// HandleWebSocketConnection establishes a web socket connection subscribed to the <room> query param
+// and reads the frames coming through it
 func (h *PostHandler) HandleWebSocketConnection(w http.ResponseWriter, r *http.Request) {
+	user := userFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	roomID := r.URL.Query().Get(roomParam)
+	if roomID == "" {
+		http.Error(w, "Missing room", http.StatusBadRequest)
+		return
+	}
+
+	member, err := h.RoomService.IsMember(r.Context(), roomID, user)
+	if err != nil {
+		log.Printf("error checking room membership: %s", err)
+		http.Error(w, "Failed to check room membership", http.StatusInternalServerError)
+		return
+	}
+	if !member {
+		http.Error(w, "Join the room before subscribing to it", http.StatusForbidden)
+		return
+	}
+
 	upgrader := websocket.Upgrader{
 		CheckOrigin: func(r *http.Request) bool {
 			return true
@@ -48,57 +118,130 @@ func (h *PostHandler) HandleWebSocketConnection(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	h.readMessages(r.Context(), conn)
-}
+	c := h.Hub.newClient(conn, user, roomID)
+	go c.writePump()
 
-// readMessages watches for messages coming through the websocket connection and queues them in the broadcast channel
-func (h *PostHandler) readMessages(ctx context.Context, conn *websocket.Conn) {
-	defer conn.Close()
+	h.readMessages(r.Context(), c)
+}
 
-	clients[conn] = true
+// readMessages watches for frames coming through the websocket connection until it is closed
+// the author of every post is the user bound to the connection, regardless of what the payload claims
+// the peer must answer the pings of the write pump, otherwise the read deadline expires and the client is unregistered
+func (h *PostHandler) readMessages(ctx context.Context, c *client) {
+	h.Hub.register <- c
+	h.Service.UserJoined(c.user, c.roomID, h.Hub.broadcast)
+
+	defer func() {
+		h.Hub.unregister <- c
+		c.conn.Close()
+		h.Service.UserLeft(c.user, c.roomID, h.Hub.broadcast)
+	}()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
 
 	for {
-		_, msg, err := conn.ReadMessage()
+		_, msg, err := c.conn.ReadMessage()
 		if err != nil {
 			log.Printf("error getting reader: %s", err)
+			return
 		}
 
-		var post *model.Post
-		if err := json.Unmarshal(msg, &post); err != nil {
-			log.Printf("error getting post from json: %s", err)
+		var frame model.Frame
+		if err := json.Unmarshal(msg, &frame); err != nil {
+			log.Printf("error getting frame from json: %s", err)
+			c.sendError("invalid frame")
+			continue
 		}
 
-		stockCode, err := h.CommandService.ParseCommand(post.Message)
-		if err := json.Unmarshal(msg, &post); err != nil {
-			log.Printf("error parsing the command: %s", err)
+		switch frame.Type {
+		case model.FrameHistory:
+			h.sendHistory(ctx, c, frame.Limit)
+		case model.FramePostCreate:
+			h.createPost(ctx, c, frame.Message)
+		default:
+			c.sendError(fmt.Sprintf("unknown frame type: %q", frame.Type))
 		}
+	}
+}
 
-		if stockCode != "" {
-			// if the message is a command to query a stock, process the command asynchronously
-			// share the broadcast channel, so it can send the message back to the chatroom
-			go h.CommandService.ProcessCommand(stockCode)
-			go h.CommandService.BroadcastCommand(broadcast)
+// sendHistory sends the recent posts of the room only to the client requesting them
+func (h *PostHandler) sendHistory(ctx context.Context, c *client, limit int) {
+	posts, err := h.Service.GetHistory(ctx, c.roomID, limit)
+	if err != nil {
+		log.Printf("error getting history: %s", err)
+		c.sendError("failed to get the room history")
+		return
+	}
 
-			continue
-		}
+	c.sendEvent(&model.Event{
+		Type:   model.EventHistory,
+		RoomID: c.roomID,
+		Data:   posts,
+	})
+}
+
+// createPost creates a post in the room of the client, or processes it if it is a command
+func (h *PostHandler) createPost(ctx context.Context, c *client, message string) {
+	if strings.TrimSpace(message) == "" {
+		return
+	}
+
+	post := &model.Post{
+		UserID:  c.user.ID.String(),
+		User:    c.user,
+		RoomID:  c.roomID,
+		Message: message,
+	}
 
-		if err := h.Service.CreatePost(ctx, post, broadcast); err != nil {
-			log.Printf("error creating post: %s", err)
+	if h.CommandService.IsCommand(post.Message) {
+		// commands are never posted as chat, their reply is only sent to the requester
+		// the answers of the bot are sent back to the chatroom by the BroadcastCommands goroutine
+		if reply := h.CommandService.ExecuteCommand(ctx, post); reply != nil {
+			c.sendEvent(&model.Event{
+				Type:   model.EventCommandReply,
+				RoomID: c.roomID,
+				Data:   reply,
+			})
 		}
+		return
+	}
+
+	if err := h.Service.CreatePost(ctx, post, h.Hub.broadcast); err != nil {
+		log.Printf("error creating post: %s", err)
+		c.sendError("failed to send the message")
 	}
 }
 
-// WriteMessages watches for messages in the broadcast channel and send them to all connected clients
-func (h *PostHandler) WriteMessages() {
-	for {
-		msg := <-broadcast
-
-		for client := range clients {
-			err := client.WriteMessage(websocket.TextMessage, msg)
-			if err != nil {
-				delete(clients, client)
-				client.Close()
-			}
-		}
+// BroadcastCommands watches for the quotes requested by the clients and sends them to the broadcast channel of the hub
+func (h *PostHandler) BroadcastCommands() {
+	h.CommandService.BroadcastCommand(h.Hub.broadcast)
+}
+
+// BroadcastAlerts watches for the alerts triggered by the bot and sends them to the broadcast channel of the hub
+func (h *PostHandler) BroadcastAlerts() {
+	h.CommandService.BroadcastAlerts(h.Hub.broadcast)
+}
+
+// sendEvent sends an event only to this client
+func (c *client) sendEvent(event *model.Event) {
+	bEvent, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("error marshaling event: %s", err)
+		return
 	}
+
+	c.enqueue(bEvent)
+}
+
+// sendError sends an error event only to this client
+func (c *client) sendError(message string) {
+	c.sendEvent(&model.Event{
+		Type:   model.EventError,
+		RoomID: c.roomID,
+		Data:   &model.ErrorData{Message: message},
+	})
 }
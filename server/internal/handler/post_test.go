@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"server/internal/model"
+	mock_repo "server/internal/repo/mocks"
+	"server/internal/service"
+	mock_service "server/internal/service/mocks"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebSocketPostsIgnoreTheClaimedAuthor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := &model.User{ID: uuid.New(), Username: "Alice"}
+
+	created := make(chan *model.Post, 1)
+
+	mockPosts := mock_service.NewMockPostService(ctrl)
+	mockPosts.EXPECT().UserJoined(user, roomID, gomock.Any())
+	mockPosts.EXPECT().UserLeft(user, roomID, gomock.Any()).AnyTimes()
+	mockPosts.EXPECT().CreatePost(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, post *model.Post, broadcast chan *model.Broadcast) error {
+			created <- post
+			return nil
+		})
+
+	mockCommands := mock_service.NewMockCommmandService(ctrl)
+	mockCommands.EXPECT().IsCommand(gomock.Any()).Return(false)
+
+	mockRooms := mock_service.NewMockRoomService(ctrl)
+	mockRooms.EXPECT().IsMember(gomock.Any(), roomID, user).Return(true, nil)
+
+	handler := NewPostHandler(mockPosts, mockCommands, mockRooms, newLoopbackHub(t))
+
+	// the session user is set as the AuthMiddleware would
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.HandleWebSocketConnection(w, r.WithContext(context.WithValue(r.Context(), userContextKey, user)))
+	}))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?room=" + roomID
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to dial the websocket: %s", err)
+	}
+	defer conn.Close()
+
+	forged := `{"type":"post.create","message":"Hello!","userID":"f1c21d1d-3411-4bfd-a99f-8fc52dc65bb5","user":{"username":"Bob"}}`
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(forged)); err != nil {
+		t.Fatalf("Failed to write the frame: %s", err)
+	}
+
+	select {
+	case post := <-created:
+		assert.Equal(t, user.ID.String(), post.UserID)
+		assert.Equal(t, "Alice", post.User.Username)
+		assert.Equal(t, "Hello!", post.Message)
+		assert.Equal(t, roomID, post.RoomID)
+	case <-time.After(time.Second):
+		t.Fatal("Expected the post to be created")
+	}
+}
+
+func TestHandleGetPosts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now().UTC()
+	posts := make([]*model.Post, 4)
+	for i := range posts {
+		timestamp := now.Add(-time.Duration(i) * time.Minute)
+		posts[i] = &model.Post{ID: uuid.New(), RoomID: roomID, Message: fmt.Sprintf("Post %d", i), Timestamp: &timestamp}
+	}
+
+	mockRepo := mock_repo.NewMockPostRepo(ctrl)
+	// one post past the limit means there are more pages
+	mockRepo.EXPECT().GetRecentPosts(gomock.Any(), roomID, 3).Return(posts[:3], nil)
+	mockRepo.EXPECT().GetPostsBefore(gomock.Any(), roomID, gomock.Any(), 3).DoAndReturn(
+		func(ctx context.Context, roomID string, before *model.Cursor, limit int) ([]*model.Post, error) {
+			assert.Equal(t, posts[1].ID, before.ID, "Expected the cursor of the last post of the first page")
+			return posts[2:], nil
+		})
+
+	mockRooms := mock_service.NewMockRoomService(ctrl)
+	mockRooms.EXPECT().IsMember(gomock.Any(), roomID, sessionUser).Return(true, nil).Times(3)
+
+	router := mux.NewRouter()
+	NewPostHandler(service.NewPostService(mockRepo), nil, mockRooms, nil).Attach(router)
+
+	rec := serveAs(router, httptest.NewRequest("GET", "/rooms/"+roomID+"/posts?limit=2", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var page model.PostPage
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+		t.Fatalf("Failed to unmarshal the page: %s", err)
+	}
+	assert.True(t, page.HasMore)
+	assert.Len(t, page.Posts, 2)
+
+	// the before cursor of the page fetches the older posts, exactly the limit is the last page
+	rec = serveAs(router, httptest.NewRequest("GET", "/rooms/"+roomID+"/posts?limit=2&before="+page.Before, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	page = model.PostPage{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+		t.Fatalf("Failed to unmarshal the page: %s", err)
+	}
+	assert.False(t, page.HasMore)
+	assert.Len(t, page.Posts, 2)
+	assert.Equal(t, "Post 2", page.Posts[0].Message)
+
+	rec = serveAs(router, httptest.NewRequest("GET", "/rooms/"+roomID+"/posts?before=not-a-cursor", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestHandleGetPostsRequiresRoomMembership(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRooms := mock_service.NewMockRoomService(ctrl)
+	mockRooms.EXPECT().IsMember(gomock.Any(), roomID, sessionUser).Return(false, nil)
+
+	router := mux.NewRouter()
+	NewPostHandler(mock_service.NewMockPostService(ctrl), nil, mockRooms, nil).Attach(router)
+
+	rec := serveAs(router, httptest.NewRequest("GET", "/rooms/"+roomID+"/posts", nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"log"
+	"net/http"
+	"server/internal/model"
+	"server/internal/repo"
+	"server/internal/service"
+)
+
+type RoomHandler struct {
+	Service service.RoomService
+}
+
+// NewRoomHandler builds a handler and injects its dependencies
+func NewRoomHandler(s service.RoomService) *RoomHandler {
+	return &RoomHandler{
+		Service: s,
+	}
+}
+
+// Attach attaches the room endpoints to the router, which must be protected by the AuthMiddleware
+func (h *RoomHandler) Attach(r *mux.Router) {
+	r.HandleFunc("/rooms", h.HandleListRooms).Methods("GET", "OPTIONS")
+	r.HandleFunc("/rooms", h.HandleCreateRoom).Methods("POST", "OPTIONS")
+	r.HandleFunc("/rooms/{id}/join", h.HandleJoinRoom).Methods("POST", "OPTIONS")
+}
+
+// HandleListRooms lists all the available rooms
+func (h *RoomHandler) HandleListRooms(w http.ResponseWriter, r *http.Request) {
+	rooms, err := h.Service.GetRooms(r.Context())
+	if err != nil {
+		log.Printf("error getting rooms: %s", err)
+		http.Error(w, "Failed to get rooms", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rooms)
+}
+
+// HandleCreateRoom creates a new room and joins its creator to it
+func (h *RoomHandler) HandleCreateRoom(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	room := &model.Room{}
+	if err := json.NewDecoder(r.Body).Decode(room); err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	room, err := h.Service.CreateRoom(r.Context(), room, userFromContext(r.Context()))
+	if errors.Is(err, service.ErrInvalidRoomName) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, repo.ErrDuplicateRoom) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("error creating room: %s", err)
+		http.Error(w, "Failed to create room", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, room)
+}
+
+// HandleJoinRoom makes the session user a member of the room
+func (h *RoomHandler) HandleJoinRoom(w http.ResponseWriter, r *http.Request) {
+	room, err := h.Service.JoinRoom(r.Context(), mux.Vars(r)["id"], userFromContext(r.Context()))
+	if errors.Is(err, service.ErrRoomNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("error joining room: %s", err)
+		http.Error(w, "Failed to join room", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, room)
+}
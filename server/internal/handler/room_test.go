@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"server/internal/model"
+	"server/internal/repo"
+	"server/internal/service"
+	mock_service "server/internal/service/mocks"
+	"strings"
+	"testing"
+)
+
+var sessionUser = &model.User{ID: uuid.New(), Username: "Alice"}
+
+// serveAs serves the request with the router as the session user, as the AuthMiddleware would
+func serveAs(router *mux.Router, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req.WithContext(context.WithValue(req.Context(), userContextKey, sessionUser)))
+	return rec
+}
+
+func TestHandleCreateRoom(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	created := &model.Room{ID: uuid.MustParse(roomID), Name: "stocks"}
+
+	mockService := mock_service.NewMockRoomService(ctrl)
+	mockService.EXPECT().CreateRoom(gomock.Any(), &model.Room{Name: "stocks"}, sessionUser).Return(created, nil)
+	mockService.EXPECT().CreateRoom(gomock.Any(), &model.Room{Name: ""}, sessionUser).Return(nil, service.ErrInvalidRoomName)
+	mockService.EXPECT().CreateRoom(gomock.Any(), &model.Room{Name: "general"}, sessionUser).Return(nil, repo.ErrDuplicateRoom)
+
+	router := mux.NewRouter()
+	NewRoomHandler(mockService).Attach(router)
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{name: "created", body: `{"name":"stocks"}`, code: http.StatusCreated},
+		{name: "empty name", body: `{"name":""}`, code: http.StatusBadRequest},
+		{name: "duplicate name", body: `{"name":"general"}`, code: http.StatusConflict},
+		{name: "malformed body", body: `{`, code: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveAs(router, httptest.NewRequest("POST", "/rooms", strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.code, rec.Code)
+		})
+	}
+}
+
+func TestHandleListRooms(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rooms := []*model.Room{{ID: uuid.MustParse(roomID), Name: "general"}}
+
+	mockService := mock_service.NewMockRoomService(ctrl)
+	mockService.EXPECT().GetRooms(gomock.Any()).Return(rooms, nil)
+
+	router := mux.NewRouter()
+	NewRoomHandler(mockService).Attach(router)
+
+	rec := serveAs(router, httptest.NewRequest("GET", "/rooms", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"name":"general"`)
+}
+
+func TestHandleJoinRoom(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	missing := "f1c21d1d-3411-4bfd-a99f-8fc52dc65bb5"
+
+	mockService := mock_service.NewMockRoomService(ctrl)
+	mockService.EXPECT().JoinRoom(gomock.Any(), roomID, sessionUser).Return(&model.Room{ID: uuid.MustParse(roomID), Name: "general"}, nil)
+	mockService.EXPECT().JoinRoom(gomock.Any(), missing, sessionUser).Return(nil, service.ErrRoomNotFound)
+
+	router := mux.NewRouter()
+	NewRoomHandler(mockService).Attach(router)
+
+	rec := serveAs(router, httptest.NewRequest("POST", "/rooms/"+roomID+"/join", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"name":"general"`)
+
+	rec = serveAs(router, httptest.NewRequest("POST", "/rooms/"+missing+"/join", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestWebSocketRequiresRoomMembership(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRooms := mock_service.NewMockRoomService(ctrl)
+	mockRooms.EXPECT().IsMember(gomock.Any(), roomID, sessionUser).Return(false, nil)
+
+	router := mux.NewRouter()
+	NewPostHandler(nil, nil, mockRooms, nil).Attach(router)
+
+	rec := serveAs(router, httptest.NewRequest("GET", "/ws?room="+roomID, nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = serveAs(router, httptest.NewRequest("GET", "/ws", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gorilla/mux"
+	"log"
+	"net/http"
+	"server/internal/service"
+)
+
+type SymbolHandler struct {
+	CommandService service.CommmandService
+}
+
+// NewSymbolHandler builds a handler and injects its dependencies
+func NewSymbolHandler(cs service.CommmandService) *SymbolHandler {
+	return &SymbolHandler{
+		CommandService: cs,
+	}
+}
+
+// Attach attaches the symbol search endpoint to the router, which must be protected by the AuthMiddleware
+func (h *SymbolHandler) Attach(r *mux.Router) {
+	r.HandleFunc("/symbols", h.HandleSearchSymbols).Methods("GET", "OPTIONS")
+}
+
+// HandleSearchSymbols returns the symbols of the catalog of the bot matching the <q> query param
+func (h *SymbolHandler) HandleSearchSymbols(w http.ResponseWriter, r *http.Request) {
+	symbols, err := h.CommandService.SearchSymbols(r.Context(), r.URL.Query().Get("q"))
+	if errors.Is(err, service.ErrInvalidQuery) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, service.ErrBotTimeout) {
+		http.Error(w, err.Error(), http.StatusGatewayTimeout)
+		return
+	}
+	if err != nil {
+		log.Printf("error searching symbols: %s", err)
+		http.Error(w, "Failed to search symbols", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, symbols)
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"server/internal/model"
+	"server/internal/service"
+	mock_service "server/internal/service/mocks"
+	"testing"
+)
+
+func TestHandleSearchSymbols(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_service.NewMockCommmandService(ctrl)
+	mockService.EXPECT().SearchSymbols(gomock.Any(), "apple").Return([]*model.Symbol{{Code: "aapl.us", Name: "Apple Inc.", Exchange: "NASDAQ"}}, nil)
+	mockService.EXPECT().SearchSymbols(gomock.Any(), "").Return(nil, service.ErrInvalidQuery)
+	mockService.EXPECT().SearchSymbols(gomock.Any(), "tesla").Return(nil, service.ErrBotTimeout)
+	mockService.EXPECT().SearchSymbols(gomock.Any(), "msft").Return(nil, errors.New("connection closed"))
+
+	router := mux.NewRouter()
+	NewSymbolHandler(mockService).Attach(router)
+
+	tests := []struct {
+		name  string
+		query string
+		code  int
+		body  string
+	}{
+		{name: "found", query: "apple", code: http.StatusOK, body: `[{"code":"aapl.us","name":"Apple Inc.","exchange":"NASDAQ"}]`},
+		{name: "invalid query", query: "", code: http.StatusBadRequest},
+		{name: "bot timeout", query: "tesla", code: http.StatusGatewayTimeout},
+		{name: "publish failure", query: "msft", code: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveAs(router, httptest.NewRequest("GET", "/symbols?q="+tt.query, nil))
+
+			assert.Equal(t, tt.code, rec.Code)
+			if tt.body != "" {
+				assert.JSONEq(t, tt.body, rec.Body.String())
+			}
+		})
+	}
+}
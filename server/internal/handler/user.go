@@ -59,7 +59,7 @@ func (h *UserHandler) HandleSignup(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonUser)
 }
 
-// HandleLogin logs a user in
+// HandleLogin logs a user in and returns a session token to authenticate the following requests
 func (h *UserHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -74,15 +74,20 @@ func (h *UserHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user = h.Service.LoginUser(r.Context(), user)
+	session, err := h.Service.LoginUser(r.Context(), user)
+	if err != nil {
+		log.Printf("error logging user in: %s", err)
+		http.Error(w, "Invalid username or password", http.StatusUnauthorized)
+		return
+	}
 
-	jsonUser, err := json.Marshal(user)
+	jsonSession, err := json.Marshal(session)
 	if err != nil {
-		log.Printf("error getting user to json: %s", err)
+		log.Printf("error getting session to json: %s", err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(http.StatusOK)
-	w.Write(jsonUser)
+	w.Write(jsonSession)
 }
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"server/internal/model"
+	"server/internal/service"
 	mock_service "server/internal/service/mocks"
 	"testing"
 )
@@ -55,7 +56,12 @@ func TestHandleLogin(t *testing.T) {
 		Password: "12345",
 	}
 
-	mockService.EXPECT().LoginUser(gomock.Any(), gomock.Any()).Return(user)
+	session := &model.Session{
+		Token: "signed-token",
+		User:  &model.User{Username: "Bob"},
+	}
+
+	mockService.EXPECT().LoginUser(gomock.Any(), gomock.Any()).Return(session, nil)
 
 	userJSON, err := json.Marshal(user)
 	if err != nil {
@@ -72,4 +78,34 @@ func TestHandleLogin(t *testing.T) {
 	}
 
 	assert.Contains(t, rec.Body.String(), "\"username\":\"Bob\"")
+	assert.Contains(t, rec.Body.String(), "\"token\":\"signed-token\"")
+}
+
+func TestHandleLoginInvalidCredentials(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_service.NewMockUserService(ctrl)
+	handler := NewUserHandler(mockService)
+
+	user := &model.User{
+		Username: "Bob",
+		Password: "wrong",
+	}
+
+	mockService.EXPECT().LoginUser(gomock.Any(), gomock.Any()).Return(nil, service.ErrInvalidCredentials)
+
+	userJSON, err := json.Marshal(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("POST", "/login", bytes.NewReader(userJSON))
+	rec := httptest.NewRecorder()
+
+	handler.HandleLogin(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status code %d, but got %d", http.StatusUnauthorized, rec.Code)
+	}
 }
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"log"
+	"net/http"
+	"server/internal/model"
+	"server/internal/service"
+)
+
+type WatchlistHandler struct {
+	Service service.WatchlistService
+}
+
+// NewWatchlistHandler builds a handler and injects its dependencies
+func NewWatchlistHandler(s service.WatchlistService) *WatchlistHandler {
+	return &WatchlistHandler{
+		Service: s,
+	}
+}
+
+// Attach attaches the watchlist endpoints to the router, which must be protected by the AuthMiddleware
+func (h *WatchlistHandler) Attach(r *mux.Router) {
+	r.HandleFunc("/users/me/watchlist", h.HandleGetWatchlist).Methods("GET", "OPTIONS")
+	r.HandleFunc("/users/me/watchlist", h.HandleWatch).Methods("POST", "OPTIONS")
+	r.HandleFunc("/users/me/watchlist/{symbol}", h.HandleUnwatch).Methods("DELETE", "OPTIONS")
+}
+
+// HandleGetWatchlist returns the stock codes watched by the session user
+func (h *WatchlistHandler) HandleGetWatchlist(w http.ResponseWriter, r *http.Request) {
+	watchlist, err := h.Service.GetWatchlist(r.Context(), userFromContext(r.Context()))
+	if err != nil {
+		log.Printf("error getting watchlist: %s", err)
+		http.Error(w, "Failed to get watchlist", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, watchlist)
+}
+
+// HandleWatch adds the stock code of the request body to the watchlist of the session user
+func (h *WatchlistHandler) HandleWatch(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	req := &model.WatchRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	watchlist, err := h.Service.Watch(r.Context(), userFromContext(r.Context()), req.Symbol)
+	if errors.Is(err, service.ErrInvalidSymbol) || errors.Is(err, service.ErrWatchlistFull) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("error watching stock: %s", err)
+		http.Error(w, "Failed to update watchlist", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, watchlist)
+}
+
+// HandleUnwatch removes the <symbol> stock code from the watchlist of the session user
+func (h *WatchlistHandler) HandleUnwatch(w http.ResponseWriter, r *http.Request) {
+	watchlist, err := h.Service.Unwatch(r.Context(), userFromContext(r.Context()), mux.Vars(r)["symbol"])
+	if errors.Is(err, service.ErrInvalidSymbol) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, service.ErrNotWatched) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("error unwatching stock: %s", err)
+		http.Error(w, "Failed to update watchlist", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, watchlist)
+}
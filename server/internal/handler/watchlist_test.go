@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"server/internal/model"
+	"server/internal/service"
+	mock_service "server/internal/service/mocks"
+	"strings"
+	"testing"
+)
+
+func TestHandleGetWatchlist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_service.NewMockWatchlistService(ctrl)
+	mockService.EXPECT().GetWatchlist(gomock.Any(), sessionUser).Return(&model.Watchlist{Symbols: []string{"aapl.us", "msft.us"}}, nil)
+
+	router := mux.NewRouter()
+	NewWatchlistHandler(mockService).Attach(router)
+
+	rec := serveAs(router, httptest.NewRequest("GET", "/users/me/watchlist", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"symbols":["aapl.us","msft.us"]}`, rec.Body.String())
+}
+
+func TestHandleWatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_service.NewMockWatchlistService(ctrl)
+	mockService.EXPECT().Watch(gomock.Any(), sessionUser, "msft.us").Return(&model.Watchlist{Symbols: []string{"aapl.us", "msft.us"}}, nil)
+	mockService.EXPECT().Watch(gomock.Any(), sessionUser, "aapl.us,msft.us").Return(nil, service.ErrInvalidSymbol)
+	mockService.EXPECT().Watch(gomock.Any(), sessionUser, "tsla.us").Return(nil, service.ErrWatchlistFull)
+
+	router := mux.NewRouter()
+	NewWatchlistHandler(mockService).Attach(router)
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{name: "watched", body: `{"symbol":"msft.us"}`, code: http.StatusOK},
+		{name: "invalid symbol", body: `{"symbol":"aapl.us,msft.us"}`, code: http.StatusBadRequest},
+		{name: "full watchlist", body: `{"symbol":"tsla.us"}`, code: http.StatusBadRequest},
+		{name: "malformed body", body: `{`, code: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveAs(router, httptest.NewRequest("POST", "/users/me/watchlist", strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.code, rec.Code)
+		})
+	}
+}
+
+func TestHandleUnwatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_service.NewMockWatchlistService(ctrl)
+	mockService.EXPECT().Unwatch(gomock.Any(), sessionUser, "msft.us").Return(&model.Watchlist{Symbols: []string{"aapl.us"}}, nil)
+	mockService.EXPECT().Unwatch(gomock.Any(), sessionUser, "tsla.us").Return(nil, service.ErrNotWatched)
+	mockService.EXPECT().Unwatch(gomock.Any(), sessionUser, "$").Return(nil, service.ErrInvalidSymbol)
+
+	router := mux.NewRouter()
+	NewWatchlistHandler(mockService).Attach(router)
+
+	rec := serveAs(router, httptest.NewRequest("DELETE", "/users/me/watchlist/msft.us", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"symbols":["aapl.us"]}`, rec.Body.String())
+
+	rec = serveAs(router, httptest.NewRequest("DELETE", "/users/me/watchlist/tsla.us", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serveAs(router, httptest.NewRequest("DELETE", "/users/me/watchlist/$", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -1,95 +1,207 @@
 package infra
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/streadway/amqp"
-	"log"
-	"os"
+	shared "infra"
 )
 
 type AMQPClient interface {
 	SetupAMQExchange() error
-	PublishAMQMessage(message []byte) error
+	PublishAMQMessage(ctx context.Context, routingKey string, message []byte, correlationID string) error
 	ConsumeAMQMessages() (<-chan amqp.Delivery, error)
+	PublishEvent(ctx context.Context, roomID string, payload []byte) error
+	ConsumeEvents() (<-chan amqp.Delivery, error)
+	ConsumeAlerts() (<-chan amqp.Delivery, error)
+	ConsumeDigests() (<-chan amqp.Delivery, error)
 	Close()
 }
 
+// the routing keys of the requests answered by the bot
+const (
+	StockKey   = "messages.stock"
+	HistoryKey = "messages.history"
+	ChartKey   = "messages.chart"
+	SearchKey  = "messages.search"
+	FXKey      = "messages.fx"
+	CryptoKey  = "messages.crypto"
+)
+
+// the routing keys of the messages the bot publishes on its own
+const (
+	AlertKey  = "messages.alert"
+	DigestKey = "messages.quote.digest"
+)
+
 const (
 	exchangeName = "stockchat"
-	stockKey     = "messages.stock"
 	quoteKey     = "messages.quote"
 	queueName    = "stockchat-queue-quotes"
+
+	eventsExchangeName = "stockchat-events"
+	eventsQueueName    = "stockchat-queue-events"
+	alertsQueueName    = "stockchat-queue-alerts"
+	digestsQueueName   = "stockchat-queue-digests"
+	// RoomHeader is the header carrying the room of the events published to the events exchange
+	RoomHeader = "roomID"
 )
 
 type amqpClient struct {
-	Conn *amqp.Connection
-	Ch   *amqp.Channel
+	Manager *shared.ConnectionManager
+	// replyKey is the routing key the quotes requested by this server instance are published back with
+	replyKey   string
+	replyQueue string
+	// eventsQueue receives the events of every room published by any server instance
+	eventsQueue string
 }
 
-// NewAMQPClient builds an amqp client
+// NewAMQPClient builds an amqp client on top of a single long-lived connection
 func NewAMQPClient() AMQPClient {
-	return &amqpClient{}
+	instanceID := uuid.NewString()
+
+	return &amqpClient{
+		Manager:     shared.NewConnectionManager(shared.URLFromEnv()),
+		replyKey:    fmt.Sprintf("%s.%s", quoteKey, instanceID),
+		replyQueue:  fmt.Sprintf("%s-%s", queueName, instanceID),
+		eventsQueue: fmt.Sprintf("%s-%s", eventsQueueName, instanceID),
+	}
 }
 
-// SetupAMQExchange configures and returns a connection and exchange to rabbitmq
+// SetupAMQExchange connects to rabbitmq and declares the exchanges <stockchat> and <stockchat-events>
+// it must be called once, the connection and exchange are restored automatically if rabbitmq restarts
 func (c *amqpClient) SetupAMQExchange() error {
-	user, password, host := os.Getenv("RABBITMQ_USERNAME"), os.Getenv("RABBITMQ_PASSWORD"), os.Getenv("RABBITMQ_HOST")
+	if err := c.Manager.Connect(); err != nil {
+		return err
+	}
 
-	amqpUrl := fmt.Sprintf("amqp://%s:%s@%s/", user, password, host)
+	return c.Manager.Declare(declareExchange)
+}
 
-	var err error
-	c.Conn, err = amqp.Dial(amqpUrl)
-	if err != nil {
-		return errors.New(fmt.Sprintf("error dialing amqp: %s", err))
+// PublishAMQMessage publishes a message to the amq exchange with the routing key of the request
+// the reply is expected to carry the same correlation id and to be routed with the ReplyTo key of this instance
+// the requests are persistent, so they survive a restart of rabbitmq in the durable queue of the bot
+// it gives up once ctx is done, instead of waiting for rabbitmq to be reachable again
+func (c *amqpClient) PublishAMQMessage(ctx context.Context, routingKey string, message []byte, correlationID string) error {
+	msg := amqp.Publishing{
+		ContentType:   "text/plain",
+		CorrelationId: correlationID,
+		DeliveryMode:  amqp.Persistent,
+		ReplyTo:       c.replyKey,
+		Body:          message,
 	}
 
-	c.Ch, err = c.Conn.Channel()
-	if err != nil {
-		return errors.New(fmt.Sprintf("error opening amqp channel: %s", err))
-	}
+	return c.Manager.PublishContext(ctx, exchangeName, routingKey, msg)
+}
 
-	if err = c.Ch.ExchangeDeclare(exchangeName, "topic", true, false, false, false, nil); err != nil {
-		log.Printf("error declaring exchange: %s", err)
-		return errors.New(fmt.Sprintf("error declaring amqp exchange: %s", err))
+// ConsumeAMQMessages returns the replies to the messages published by this instance
+// every instance has its own exclusive queue, so replies are not consumed by other instances
+func (c *amqpClient) ConsumeAMQMessages() (<-chan amqp.Delivery, error) {
+	return c.Manager.Consume(shared.ConsumeOptions{
+		Queue:     c.replyQueue,
+		AutoAck:   true,
+		Exclusive: true,
+		Setup: func(ch shared.Channel) error {
+			return declareQueue(ch, exchangeName, c.replyQueue, c.replyKey)
+		},
+	})
+}
+
+// PublishEvent publishes a websocket event of a room to the fan-out exchange <stockchat-events>
+// so every server instance delivers it to its own clients connected to the room
+// it gives up once ctx is done, instead of waiting for rabbitmq to be reachable again
+func (c *amqpClient) PublishEvent(ctx context.Context, roomID string, payload []byte) error {
+	msg := amqp.Publishing{
+		ContentType: "application/json",
+		Headers:     amqp.Table{RoomHeader: roomID},
+		Body:        payload,
 	}
 
-	return nil
+	return c.Manager.PublishContext(ctx, eventsExchangeName, "", msg)
 }
 
-// PublishAMQMessage publishes a message to the amq exchange
-func (c *amqpClient) PublishAMQMessage(message []byte) error {
-	msg := amqp.Publishing{
-		ContentType: "text/plain",
-		Body:        message,
+// ConsumeEvents returns the websocket events published by every server instance, this one included
+// every instance has its own exclusive queue bound to the fan-out exchange
+func (c *amqpClient) ConsumeEvents() (<-chan amqp.Delivery, error) {
+	return c.Manager.Consume(shared.ConsumeOptions{
+		Queue:     c.eventsQueue,
+		AutoAck:   true,
+		Exclusive: true,
+		Setup: func(ch shared.Channel) error {
+			return declareQueue(ch, eventsExchangeName, c.eventsQueue, "")
+		},
+	})
+}
+
+// ConsumeAlerts returns the alerts triggered by the bot
+// the queue is durable and shared by every server instance, so each alert is consumed by a single instance
+func (c *amqpClient) ConsumeAlerts() (<-chan amqp.Delivery, error) {
+	return c.Manager.Consume(shared.ConsumeOptions{
+		Queue:   alertsQueueName,
+		AutoAck: true,
+		Setup: func(ch shared.Channel) error {
+			return declareSharedQueue(ch, exchangeName, alertsQueueName, AlertKey)
+		},
+	})
+}
+
+// ConsumeDigests returns the scheduled digests posted by the bot, which do not answer any request
+// the queue is durable and shared by every server instance, so each digest is consumed by a single instance
+func (c *amqpClient) ConsumeDigests() (<-chan amqp.Delivery, error) {
+	return c.Manager.Consume(shared.ConsumeOptions{
+		Queue:   digestsQueueName,
+		AutoAck: true,
+		Setup: func(ch shared.Channel) error {
+			return declareSharedQueue(ch, exchangeName, digestsQueueName, DigestKey)
+		},
+	})
+}
+
+// Close closes the rabbitmq connection
+func (c *amqpClient) Close() {
+	c.Manager.Close()
+}
+
+// declareExchange declares the topic exchange shared by the server and the bot,
+// and the fan-out exchange shared by the server instances
+func declareExchange(ch shared.Channel) error {
+	if err := ch.ExchangeDeclare(exchangeName, "topic", true, false, false, false, nil); err != nil {
+		return errors.New(fmt.Sprintf("error declaring amqp exchange: %s", err))
 	}
-	if err := c.Ch.Publish(exchangeName, stockKey, false, false, msg); err != nil {
-		return err
+
+	if err := ch.ExchangeDeclare(eventsExchangeName, "fanout", true, false, false, false, nil); err != nil {
+		return errors.New(fmt.Sprintf("error declaring amqp events exchange: %s", err))
 	}
 
 	return nil
 }
 
-// ConsumeAMQMessages returns the messages from the subscribed queue
-func (c *amqpClient) ConsumeAMQMessages() (<-chan amqp.Delivery, error) {
-	q, err := c.Ch.QueueDeclare(queueName, false, false, false, false, nil)
+// declareQueue declares an exclusive queue bound to the exchange with the given routing key
+func declareQueue(ch shared.Channel, exchange string, name string, key string) error {
+	q, err := ch.QueueDeclare(name, false, true, true, false, nil)
 	if err != nil {
-		return nil, errors.New(fmt.Sprintf("error declaring queue: %s", err))
+		return errors.New(fmt.Sprintf("error declaring queue: %s", err))
 	}
 
-	if err = c.Ch.QueueBind(q.Name, quoteKey, exchangeName, false, nil); err != nil {
-		return nil, errors.New(fmt.Sprintf("error binding exchange to queue: %s", err))
+	if err = ch.QueueBind(q.Name, key, exchange, false, nil); err != nil {
+		return errors.New(fmt.Sprintf("error binding exchange to queue: %s", err))
 	}
 
-	messages, err := c.Ch.Consume(q.Name, "", true, false, false, false, nil)
+	return nil
+}
+
+// declareSharedQueue declares a durable queue bound to the exchange with the given routing key, consumed by every instance
+func declareSharedQueue(ch shared.Channel, exchange string, name string, key string) error {
+	q, err := ch.QueueDeclare(name, true, false, false, false, nil)
 	if err != nil {
-		return nil, errors.New(fmt.Sprintf("error consuming queued messages: %s", err))
+		return errors.New(fmt.Sprintf("error declaring queue: %s", err))
 	}
 
-	return messages, nil
-}
+	if err = ch.QueueBind(q.Name, key, exchange, false, nil); err != nil {
+		return errors.New(fmt.Sprintf("error binding exchange to queue: %s", err))
+	}
 
-func (c *amqpClient) Close() {
-	c.Conn.Close()
-	c.Ch.Close()
+	return nil
 }
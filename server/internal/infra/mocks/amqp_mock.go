@@ -5,6 +5,7 @@
 package mock_infra
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -61,18 +62,77 @@ func (mr *MockAMQPClientMockRecorder) ConsumeAMQMessages() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeAMQMessages", reflect.TypeOf((*MockAMQPClient)(nil).ConsumeAMQMessages))
 }
 
+// ConsumeAlerts mocks base method.
+func (m *MockAMQPClient) ConsumeAlerts() (<-chan amqp.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeAlerts")
+	ret0, _ := ret[0].(<-chan amqp.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeAlerts indicates an expected call of ConsumeAlerts.
+func (mr *MockAMQPClientMockRecorder) ConsumeAlerts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeAlerts", reflect.TypeOf((*MockAMQPClient)(nil).ConsumeAlerts))
+}
+
+// ConsumeDigests mocks base method.
+func (m *MockAMQPClient) ConsumeDigests() (<-chan amqp.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeDigests")
+	ret0, _ := ret[0].(<-chan amqp.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeDigests indicates an expected call of ConsumeDigests.
+func (mr *MockAMQPClientMockRecorder) ConsumeDigests() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeDigests", reflect.TypeOf((*MockAMQPClient)(nil).ConsumeDigests))
+}
+
+// ConsumeEvents mocks base method.
+func (m *MockAMQPClient) ConsumeEvents() (<-chan amqp.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeEvents")
+	ret0, _ := ret[0].(<-chan amqp.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeEvents indicates an expected call of ConsumeEvents.
+func (mr *MockAMQPClientMockRecorder) ConsumeEvents() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeEvents", reflect.TypeOf((*MockAMQPClient)(nil).ConsumeEvents))
+}
+
 // PublishAMQMessage mocks base method.
-func (m *MockAMQPClient) PublishAMQMessage(message []byte) error {
+func (m *MockAMQPClient) PublishAMQMessage(ctx context.Context, routingKey string, message []byte, correlationID string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PublishAMQMessage", message)
+	ret := m.ctrl.Call(m, "PublishAMQMessage", ctx, routingKey, message, correlationID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // PublishAMQMessage indicates an expected call of PublishAMQMessage.
-func (mr *MockAMQPClientMockRecorder) PublishAMQMessage(message interface{}) *gomock.Call {
+func (mr *MockAMQPClientMockRecorder) PublishAMQMessage(ctx, routingKey, message, correlationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishAMQMessage", reflect.TypeOf((*MockAMQPClient)(nil).PublishAMQMessage), ctx, routingKey, message, correlationID)
+}
+
+// PublishEvent mocks base method.
+func (m *MockAMQPClient) PublishEvent(ctx context.Context, roomID string, payload []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishEvent", ctx, roomID, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishEvent indicates an expected call of PublishEvent.
+func (mr *MockAMQPClientMockRecorder) PublishEvent(ctx, roomID, payload interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishAMQMessage", reflect.TypeOf((*MockAMQPClient)(nil).PublishAMQMessage), message)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishEvent", reflect.TypeOf((*MockAMQPClient)(nil).PublishEvent), ctx, roomID, payload)
 }
 
 // SetupAMQExchange mocks base method.
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	AlertAbove = ">"
+	AlertBelow = "<"
+)
+
+// Alert notifies a user in a room when the quote of a symbol crosses the threshold, it triggers only once
+type Alert struct {
+	ID          int64      `json:"id"`
+	UserID      string     `json:"userID"`
+	RoomID      string     `json:"roomID"`
+	Symbol      string     `json:"symbol"`
+	Operator    string     `json:"operator"`
+	Threshold   float64    `json:"threshold"`
+	CreatedAt   *time.Time `json:"createdAt,omitempty"`
+	TriggeredAt *time.Time `json:"triggeredAt,omitempty"`
+}
+
+// AlertNotification is published by the bot when an alert triggers
+type AlertNotification struct {
+	Alert    *Alert `json:"alert"`
+	Username string `json:"username"`
+	Message  string `json:"message"`
+	Quote    *Quote `json:"quote,omitempty"`
+}
+
+// String describes the condition of the alert, such as AAPL.US > $200.00
+func (a *Alert) String() string {
+	return fmt.Sprintf("%s %s $%.2f", strings.ToUpper(a.Symbol), a.Operator, a.Threshold)
+}
@@ -0,0 +1,7 @@
+package model
+
+// Broadcast is a payload to be delivered to every client connected to a room
+type Broadcast struct {
+	RoomID  string
+	Payload []byte
+}
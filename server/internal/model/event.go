@@ -0,0 +1,35 @@
+package model
+
+const (
+	EventPostCreated   = "post.created"
+	EventQuoteReceived = "quote.received"
+	EventCommandReply  = "command.reply"
+	EventAlertFired    = "alert.fired"
+	EventUserJoined    = "user.joined"
+	EventUserLeft      = "user.left"
+	EventHistory       = "history"
+	EventError         = "error"
+
+	FramePostCreate = "post.create"
+	FrameHistory    = "history"
+)
+
+// Event is sent to the clients through the websocket, its data only carries what changed
+type Event struct {
+	Type   string      `json:"type"`
+	RoomID string      `json:"roomID,omitempty"`
+	Data   interface{} `json:"data,omitempty"`
+}
+
+// Frame is sent by the clients through the websocket
+// post.create frames carry the message, and history frames the number of posts requested
+type Frame struct {
+	Type    string `json:"type"`
+	Message string `json:"message,omitempty"`
+	Limit   int    `json:"limit,omitempty"`
+}
+
+// ErrorData is the data of the error events
+type ErrorData struct {
+	Message string `json:"message"`
+}
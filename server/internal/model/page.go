@@ -0,0 +1,66 @@
+package model
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+// Cursor points to a post by its (timestamp, id) keyset, so pages are stable while new posts arrive
+type Cursor struct {
+	Timestamp time.Time
+	ID        uuid.UUID
+}
+
+// PostPage is a page of posts, newest first, with the cursors to fetch the older and the newer ones
+type PostPage struct {
+	Posts   []*Post `json:"posts"`
+	Before  string  `json:"before,omitempty"`
+	After   string  `json:"after,omitempty"`
+	HasMore bool    `json:"hasMore"`
+}
+
+// NewCursor returns the cursor pointing to the post
+func NewCursor(post *Post) *Cursor {
+	return &Cursor{
+		Timestamp: *post.Timestamp,
+		ID:        post.ID,
+	}
+}
+
+// ParseCursor decodes a cursor returned by Cursor.String
+func ParseCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid cursor: %s", err))
+	}
+
+	tokens := strings.Split(string(raw), "|")
+	if len(tokens) != 2 {
+		return nil, errors.New("invalid cursor: malformed keyset")
+	}
+
+	ts, err := time.Parse(time.RFC3339Nano, tokens[0])
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid cursor timestamp: %s", err))
+	}
+
+	id, err := uuid.Parse(tokens[1])
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid cursor id: %s", err))
+	}
+
+	return &Cursor{
+		Timestamp: ts,
+		ID:        id,
+	}, nil
+}
+
+// String encodes the cursor as an opaque url-safe token
+func (c *Cursor) String() string {
+	raw := fmt.Sprintf("%s|%s", c.Timestamp.Format(time.RFC3339Nano), c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
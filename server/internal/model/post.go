@@ -5,10 +5,20 @@ import (
 	"time"
 )
 
+const (
+	// PostTypeChat is a message sent by a user
+	PostTypeChat = "chat"
+	// PostTypeBot is a reply of the StockBot to a command
+	PostTypeBot = "bot"
+)
+
 type Post struct {
 	ID        uuid.UUID  `json:"id"`
 	UserID    string     `json:"userID"`
+	RoomID    string     `json:"roomID"`
 	User      *User      `json:"user" pg:"rel:has-one"`
 	Message   string     `json:"message"`
+	Type      string     `json:"type"`
+	Quotes    Quotes     `json:"quotes,omitempty"`
 	Timestamp *time.Time `json:"timestamp"`
 }
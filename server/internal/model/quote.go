@@ -0,0 +1,54 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Quote is the OHLCV quote of a stock answered by the bot, Error explains why it could not be fetched
+type Quote struct {
+	Symbol string  `json:"symbol"`
+	Date   string  `json:"date,omitempty"`
+	Time   string  `json:"time,omitempty"`
+	Open   float64 `json:"open"`
+	High   float64 `json:"high"`
+	Low    float64 `json:"low"`
+	Close  float64 `json:"close"`
+	Volume int64   `json:"volume"`
+	Source string  `json:"source,omitempty"`
+	Error  string  `json:"error,omitempty"`
+
+	// Currency is the currency of the prices, and Rate the exchange rate they were converted with from the currency of the listing
+	Currency        string  `json:"currency,omitempty"`
+	Rate            float64 `json:"rate,omitempty"`
+	ListingCurrency string  `json:"listingCurrency,omitempty"`
+}
+
+// Quotes are the quotes attached to a StockBot post, stored as a jsonb column
+type Quotes []*Quote
+
+// Value stores the quotes as json, or as null when there are none
+func (q Quotes) Value() (driver.Value, error) {
+	if len(q) == 0 {
+		return nil, nil
+	}
+
+	return json.Marshal(q)
+}
+
+// Scan reads the quotes from their json column
+func (q *Quotes) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*q = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, q)
+	case string:
+		return json.Unmarshal([]byte(v), q)
+	default:
+		return errors.New(fmt.Sprintf("error scanning quotes: unexpected type %T", src))
+	}
+}
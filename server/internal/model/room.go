@@ -0,0 +1,13 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type Room struct {
+	ID        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	CreatedBy string     `json:"createdBy,omitempty"`
+	CreatedAt *time.Time `json:"createdAt"`
+}
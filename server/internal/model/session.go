@@ -0,0 +1,6 @@
+package model
+
+type Session struct {
+	Token string `json:"token"`
+	User  *User  `json:"user"`
+}
@@ -0,0 +1,8 @@
+package model
+
+// Symbol is a stock code of the symbol catalog of the bot, with the name of its company
+type Symbol struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Exchange string `json:"exchange,omitempty"`
+}
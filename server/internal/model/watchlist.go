@@ -0,0 +1,11 @@
+package model
+
+// Watchlist is the list of stock codes watched by a user, in the order they were added
+type Watchlist struct {
+	Symbols []string `json:"symbols"`
+}
+
+// WatchRequest is the body of the requests adding a stock code to the watchlist
+type WatchRequest struct {
+	Symbol string `json:"symbol"`
+}
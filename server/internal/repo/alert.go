@@ -0,0 +1,98 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"server/db"
+	"server/internal/model"
+)
+
+type AlertRepo interface {
+	CreateAlert(ctx context.Context, alert *model.Alert) (*model.Alert, error)
+	GetAlerts(ctx context.Context, userID string, roomID string) ([]*model.Alert, error)
+	CountAlerts(ctx context.Context, userID string) (int, error)
+	DeleteAlert(ctx context.Context, id int64, userID string) (bool, error)
+}
+
+type alertRepository struct {
+	db db.DB
+}
+
+// NewAlertRepository builds an alertRepository and injects its dependencies
+func NewAlertRepository(db db.DB) AlertRepo {
+	return &alertRepository{db: db}
+}
+
+// CreateAlert inserts a new active alert into the database
+func (r *alertRepository) CreateAlert(ctx context.Context, alert *model.Alert) (*model.Alert, error) {
+	query := `INSERT INTO alerts(user_id, room_id, symbol, operator, threshold) VALUES ($1, $2, $3, $4, $5) returning id, created_at`
+
+	err := r.db.QueryRowContext(ctx, query, alert.UserID, alert.RoomID, alert.Symbol, alert.Operator, alert.Threshold).
+		Scan(&alert.ID, &alert.CreatedAt)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("error inserting alert: %s", err))
+	}
+
+	return alert, nil
+}
+
+// GetAlerts returns the active alerts of a user in a room, oldest first
+func (r *alertRepository) GetAlerts(ctx context.Context, userID string, roomID string) ([]*model.Alert, error) {
+	query := `
+		SELECT id, user_id, room_id, symbol, operator, threshold, created_at
+		FROM alerts
+		WHERE user_id = $1 AND room_id = $2 AND triggered_at IS NULL
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, roomID)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("error querying the alerts table: %s", err))
+	}
+	defer rows.Close()
+
+	var alerts []*model.Alert
+	for rows.Next() {
+		alert := &model.Alert{}
+		if err := rows.Scan(&alert.ID, &alert.UserID, &alert.RoomID, &alert.Symbol, &alert.Operator, &alert.Threshold, &alert.CreatedAt); err != nil {
+			return nil, errors.New(fmt.Sprintf("error scanning rows: %s", err))
+		}
+		alerts = append(alerts, alert)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.New(fmt.Sprintf("error during rows iteration: %s", err))
+	}
+
+	return alerts, nil
+}
+
+// CountAlerts returns the number of active alerts of a user in every room
+func (r *alertRepository) CountAlerts(ctx context.Context, userID string) (int, error) {
+	var count int
+
+	query := `SELECT count(*) FROM alerts WHERE user_id = $1 AND triggered_at IS NULL`
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, errors.New(fmt.Sprintf("error counting alerts: %s", err))
+	}
+
+	return count, nil
+}
+
+// DeleteAlert removes an alert of the user, returning false if the user has no alert with that id
+func (r *alertRepository) DeleteAlert(ctx context.Context, id int64, userID string) (bool, error) {
+	query := `DELETE FROM alerts WHERE id = $1 AND user_id = $2`
+
+	res, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return false, errors.New(fmt.Sprintf("error deleting alert: %s", err))
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, errors.New(fmt.Sprintf("error deleting alert: %s", err))
+	}
+
+	return n > 0, nil
+}
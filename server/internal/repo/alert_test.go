@@ -0,0 +1,63 @@
+package repo
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"server/internal/model"
+	"testing"
+	"time"
+)
+
+func TestCreateAlert(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open a stub database connection: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewAlertRepository(db)
+
+	mock.ExpectQuery("INSERT INTO alerts").
+		WithArgs("48ccb5c1-9a19-42cd-bd41-3ac5c8af1108", "6b1d0f5e-2a1c-4f0e-9d7a-3c5e8b2f4a10", "aapl.us", model.AlertAbove, 200.0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(7, time.Now()))
+
+	alert, err := repo.CreateAlert(context.Background(), &model.Alert{
+		UserID:    "48ccb5c1-9a19-42cd-bd41-3ac5c8af1108",
+		RoomID:    "6b1d0f5e-2a1c-4f0e-9d7a-3c5e8b2f4a10",
+		Symbol:    "aapl.us",
+		Operator:  model.AlertAbove,
+		Threshold: 200,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), alert.ID)
+	assert.NotNil(t, alert.CreatedAt)
+	assert.Equal(t, "AAPL.US > $200.00", alert.String())
+}
+
+func TestDeleteAlert(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open a stub database connection: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewAlertRepository(db)
+
+	mock.ExpectExec("DELETE FROM alerts").WithArgs(int64(7), "48ccb5c1-9a19-42cd-bd41-3ac5c8af1108").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM alerts").WithArgs(int64(8), "48ccb5c1-9a19-42cd-bd41-3ac5c8af1108").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	deleted, err := repo.DeleteAlert(context.Background(), 7, "48ccb5c1-9a19-42cd-bd41-3ac5c8af1108")
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+
+	// the alert does not exist or belongs to another user
+	deleted, err = repo.DeleteAlert(context.Background(), 8, "48ccb5c1-9a19-42cd-bd41-3ac5c8af1108")
+	assert.NoError(t, err)
+	assert.False(t, deleted)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
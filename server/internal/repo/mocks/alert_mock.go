@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: alert.go
+
+// Package mock_repo is a generated GoMock package.
+package mock_repo
+
+import (
+	context "context"
+	reflect "reflect"
+	model "server/internal/model"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAlertRepo is a mock of AlertRepo interface.
+type MockAlertRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockAlertRepoMockRecorder
+}
+
+// MockAlertRepoMockRecorder is the mock recorder for MockAlertRepo.
+type MockAlertRepoMockRecorder struct {
+	mock *MockAlertRepo
+}
+
+// NewMockAlertRepo creates a new mock instance.
+func NewMockAlertRepo(ctrl *gomock.Controller) *MockAlertRepo {
+	mock := &MockAlertRepo{ctrl: ctrl}
+	mock.recorder = &MockAlertRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAlertRepo) EXPECT() *MockAlertRepoMockRecorder {
+	return m.recorder
+}
+
+// CountAlerts mocks base method.
+func (m *MockAlertRepo) CountAlerts(ctx context.Context, userID string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountAlerts", ctx, userID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountAlerts indicates an expected call of CountAlerts.
+func (mr *MockAlertRepoMockRecorder) CountAlerts(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAlerts", reflect.TypeOf((*MockAlertRepo)(nil).CountAlerts), ctx, userID)
+}
+
+// CreateAlert mocks base method.
+func (m *MockAlertRepo) CreateAlert(ctx context.Context, alert *model.Alert) (*model.Alert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAlert", ctx, alert)
+	ret0, _ := ret[0].(*model.Alert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAlert indicates an expected call of CreateAlert.
+func (mr *MockAlertRepoMockRecorder) CreateAlert(ctx, alert interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAlert", reflect.TypeOf((*MockAlertRepo)(nil).CreateAlert), ctx, alert)
+}
+
+// DeleteAlert mocks base method.
+func (m *MockAlertRepo) DeleteAlert(ctx context.Context, id int64, userID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAlert", ctx, id, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAlert indicates an expected call of DeleteAlert.
+func (mr *MockAlertRepoMockRecorder) DeleteAlert(ctx, id, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAlert", reflect.TypeOf((*MockAlertRepo)(nil).DeleteAlert), ctx, id, userID)
+}
+
+// GetAlerts mocks base method.
+func (m *MockAlertRepo) GetAlerts(ctx context.Context, userID, roomID string) ([]*model.Alert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlerts", ctx, userID, roomID)
+	ret0, _ := ret[0].([]*model.Alert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAlerts indicates an expected call of GetAlerts.
+func (mr *MockAlertRepoMockRecorder) GetAlerts(ctx, userID, roomID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlerts", reflect.TypeOf((*MockAlertRepo)(nil).GetAlerts), ctx, userID, roomID)
+}
@@ -50,17 +50,47 @@ func (mr *MockPostRepoMockRecorder) CreatePost(ctx, post interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePost", reflect.TypeOf((*MockPostRepo)(nil).CreatePost), ctx, post)
 }
 
+// GetPostsAfter mocks base method.
+func (m *MockPostRepo) GetPostsAfter(ctx context.Context, roomID string, after *model.Cursor, limit int) ([]*model.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPostsAfter", ctx, roomID, after, limit)
+	ret0, _ := ret[0].([]*model.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPostsAfter indicates an expected call of GetPostsAfter.
+func (mr *MockPostRepoMockRecorder) GetPostsAfter(ctx, roomID, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostsAfter", reflect.TypeOf((*MockPostRepo)(nil).GetPostsAfter), ctx, roomID, after, limit)
+}
+
+// GetPostsBefore mocks base method.
+func (m *MockPostRepo) GetPostsBefore(ctx context.Context, roomID string, before *model.Cursor, limit int) ([]*model.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPostsBefore", ctx, roomID, before, limit)
+	ret0, _ := ret[0].([]*model.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPostsBefore indicates an expected call of GetPostsBefore.
+func (mr *MockPostRepoMockRecorder) GetPostsBefore(ctx, roomID, before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostsBefore", reflect.TypeOf((*MockPostRepo)(nil).GetPostsBefore), ctx, roomID, before, limit)
+}
+
 // GetRecentPosts mocks base method.
-func (m *MockPostRepo) GetRecentPosts(ctx context.Context, limit int) ([]*model.Post, error) {
+func (m *MockPostRepo) GetRecentPosts(ctx context.Context, roomID string, limit int) ([]*model.Post, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRecentPosts", ctx, limit)
+	ret := m.ctrl.Call(m, "GetRecentPosts", ctx, roomID, limit)
 	ret0, _ := ret[0].([]*model.Post)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetRecentPosts indicates an expected call of GetRecentPosts.
-func (mr *MockPostRepoMockRecorder) GetRecentPosts(ctx, limit interface{}) *gomock.Call {
+func (mr *MockPostRepoMockRecorder) GetRecentPosts(ctx, roomID, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecentPosts", reflect.TypeOf((*MockPostRepo)(nil).GetRecentPosts), ctx, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecentPosts", reflect.TypeOf((*MockPostRepo)(nil).GetRecentPosts), ctx, roomID, limit)
 }
@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: room.go
+
+// Package mock_repo is a generated GoMock package.
+package mock_repo
+
+import (
+	context "context"
+	reflect "reflect"
+	model "server/internal/model"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockRoomRepo is a mock of RoomRepo interface.
+type MockRoomRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockRoomRepoMockRecorder
+}
+
+// MockRoomRepoMockRecorder is the mock recorder for MockRoomRepo.
+type MockRoomRepoMockRecorder struct {
+	mock *MockRoomRepo
+}
+
+// NewMockRoomRepo creates a new mock instance.
+func NewMockRoomRepo(ctrl *gomock.Controller) *MockRoomRepo {
+	mock := &MockRoomRepo{ctrl: ctrl}
+	mock.recorder = &MockRoomRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRoomRepo) EXPECT() *MockRoomRepoMockRecorder {
+	return m.recorder
+}
+
+// AddMember mocks base method.
+func (m *MockRoomRepo) AddMember(ctx context.Context, roomID, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddMember", ctx, roomID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddMember indicates an expected call of AddMember.
+func (mr *MockRoomRepoMockRecorder) AddMember(ctx, roomID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMember", reflect.TypeOf((*MockRoomRepo)(nil).AddMember), ctx, roomID, userID)
+}
+
+// CreateRoom mocks base method.
+func (m *MockRoomRepo) CreateRoom(ctx context.Context, room *model.Room) (*model.Room, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRoom", ctx, room)
+	ret0, _ := ret[0].(*model.Room)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRoom indicates an expected call of CreateRoom.
+func (mr *MockRoomRepoMockRecorder) CreateRoom(ctx, room interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRoom", reflect.TypeOf((*MockRoomRepo)(nil).CreateRoom), ctx, room)
+}
+
+// GetRoom mocks base method.
+func (m *MockRoomRepo) GetRoom(ctx context.Context, roomID string) (*model.Room, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoom", ctx, roomID)
+	ret0, _ := ret[0].(*model.Room)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoom indicates an expected call of GetRoom.
+func (mr *MockRoomRepoMockRecorder) GetRoom(ctx, roomID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoom", reflect.TypeOf((*MockRoomRepo)(nil).GetRoom), ctx, roomID)
+}
+
+// GetRooms mocks base method.
+func (m *MockRoomRepo) GetRooms(ctx context.Context) ([]*model.Room, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRooms", ctx)
+	ret0, _ := ret[0].([]*model.Room)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRooms indicates an expected call of GetRooms.
+func (mr *MockRoomRepoMockRecorder) GetRooms(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRooms", reflect.TypeOf((*MockRoomRepo)(nil).GetRooms), ctx)
+}
+
+// IsMember mocks base method.
+func (m *MockRoomRepo) IsMember(ctx context.Context, roomID, userID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsMember", ctx, roomID, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsMember indicates an expected call of IsMember.
+func (mr *MockRoomRepoMockRecorder) IsMember(ctx, roomID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsMember", reflect.TypeOf((*MockRoomRepo)(nil).IsMember), ctx, roomID, userID)
+}
@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: watchlist.go
+
+// Package mock_repo is a generated GoMock package.
+package mock_repo
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockWatchlistRepo is a mock of WatchlistRepo interface.
+type MockWatchlistRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockWatchlistRepoMockRecorder
+}
+
+// MockWatchlistRepoMockRecorder is the mock recorder for MockWatchlistRepo.
+type MockWatchlistRepoMockRecorder struct {
+	mock *MockWatchlistRepo
+}
+
+// NewMockWatchlistRepo creates a new mock instance.
+func NewMockWatchlistRepo(ctrl *gomock.Controller) *MockWatchlistRepo {
+	mock := &MockWatchlistRepo{ctrl: ctrl}
+	mock.recorder = &MockWatchlistRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWatchlistRepo) EXPECT() *MockWatchlistRepoMockRecorder {
+	return m.recorder
+}
+
+// AddSymbol mocks base method.
+func (m *MockWatchlistRepo) AddSymbol(ctx context.Context, userID, symbol string, limit int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSymbol", ctx, userID, symbol, limit)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddSymbol indicates an expected call of AddSymbol.
+func (mr *MockWatchlistRepoMockRecorder) AddSymbol(ctx, userID, symbol, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSymbol", reflect.TypeOf((*MockWatchlistRepo)(nil).AddSymbol), ctx, userID, symbol, limit)
+}
+
+// GetSymbols mocks base method.
+func (m *MockWatchlistRepo) GetSymbols(ctx context.Context, userID string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSymbols", ctx, userID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSymbols indicates an expected call of GetSymbols.
+func (mr *MockWatchlistRepoMockRecorder) GetSymbols(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSymbols", reflect.TypeOf((*MockWatchlistRepo)(nil).GetSymbols), ctx, userID)
+}
+
+// RemoveSymbol mocks base method.
+func (m *MockWatchlistRepo) RemoveSymbol(ctx context.Context, userID, symbol string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveSymbol", ctx, userID, symbol)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveSymbol indicates an expected call of RemoveSymbol.
+func (mr *MockWatchlistRepoMockRecorder) RemoveSymbol(ctx, userID, symbol interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveSymbol", reflect.TypeOf((*MockWatchlistRepo)(nil).RemoveSymbol), ctx, userID, symbol)
+}
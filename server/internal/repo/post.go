@@ -2,16 +2,20 @@ package repo
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"server/db"
 	"server/internal/model"
+	"time"
 )
 
 type PostRepo interface {
 	CreatePost(ctx context.Context, post *model.Post) (*model.Post, error)
-	GetRecentPosts(ctx context.Context, limit int) ([]*model.Post, error)
+	GetRecentPosts(ctx context.Context, roomID string, limit int) ([]*model.Post, error)
+	GetPostsBefore(ctx context.Context, roomID string, before *model.Cursor, limit int) ([]*model.Post, error)
+	GetPostsAfter(ctx context.Context, roomID string, after *model.Cursor, limit int) ([]*model.Post, error)
 }
 
 type postRepository struct {
@@ -23,42 +27,93 @@ func NewPostRepository(db db.DB) PostRepo {
 	return &postRepository{db: db}
 }
 
-// CreatePost insert a new post into the database
+// CreatePost insert a new post into the database, posts without a type are chat messages
+// the quotes answered by the bot are stored along with the post
 func (r *postRepository) CreatePost(ctx context.Context, post *model.Post) (*model.Post, error) {
 	var lastInsertId uuid.UUID
-	query := `INSERT INTO posts(user_id, message) VALUES ($1, $2) returning (id)`
+	var timestamp time.Time
+	query := `INSERT INTO posts(user_id, room_id, message, type, quotes) VALUES ($1, $2, $3, $4, $5) returning id, timestamp`
 
-	err := r.db.QueryRowContext(ctx, query, post.UserID, post.Message).Scan(&lastInsertId)
+	if post.Type == "" {
+		post.Type = model.PostTypeChat
+	}
+
+	err := r.db.QueryRowContext(ctx, query, post.UserID, post.RoomID, post.Message, post.Type, post.Quotes).Scan(&lastInsertId, &timestamp)
 	if err != nil {
 		return &model.Post{}, err
 	}
 
 	post.ID = lastInsertId
+	post.Timestamp = &timestamp
 	return post, nil
 }
 
-// GetRecentPosts returns the last <limit> posts from the database, including the associated user data
-func (r *postRepository) GetRecentPosts(ctx context.Context, limit int) ([]*model.Post, error) {
+// GetRecentPosts returns the last <limit> posts of a room from the database, including the associated user data
+func (r *postRepository) GetRecentPosts(ctx context.Context, roomID string, limit int) ([]*model.Post, error) {
+	query := `
+		SELECT posts.id, posts.user_id, posts.room_id, posts.message, posts.type, posts.quotes, posts.timestamp, users.id, users.username 
+		FROM posts
+		INNER JOIN users ON users.id = posts.user_id
+		WHERE posts.room_id = $1
+		ORDER BY posts.timestamp DESC, posts.id DESC LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, roomID, limit)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("error querying the posts table: %s", err))
+	}
+	defer rows.Close()
+
+	return scanPosts(rows)
+}
+
+// GetPostsBefore returns the <limit> posts of a room older than the cursor, newest first
+func (r *postRepository) GetPostsBefore(ctx context.Context, roomID string, before *model.Cursor, limit int) ([]*model.Post, error) {
 	query := `
-		SELECT posts.id, posts.user_id, posts.message, posts.timestamp, users.id, users.username 
+		SELECT posts.id, posts.user_id, posts.room_id, posts.message, posts.type, posts.quotes, posts.timestamp, users.id, users.username 
 		FROM posts
 		INNER JOIN users ON users.id = posts.user_id
-		ORDER BY posts.timestamp DESC LIMIT $1
+		WHERE posts.room_id = $1 AND (posts.timestamp, posts.id) < ($2, $3)
+		ORDER BY posts.timestamp DESC, posts.id DESC LIMIT $4
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, limit)
+	rows, err := r.db.QueryContext(ctx, query, roomID, before.Timestamp, before.ID, limit)
 	if err != nil {
-		return []*model.Post{}, nil
+		return nil, errors.New(fmt.Sprintf("error querying the posts table: %s", err))
 	}
 	defer rows.Close()
 
+	return scanPosts(rows)
+}
+
+// GetPostsAfter returns the <limit> posts of a room newer than the cursor, oldest first
+func (r *postRepository) GetPostsAfter(ctx context.Context, roomID string, after *model.Cursor, limit int) ([]*model.Post, error) {
+	query := `
+		SELECT posts.id, posts.user_id, posts.room_id, posts.message, posts.type, posts.quotes, posts.timestamp, users.id, users.username 
+		FROM posts
+		INNER JOIN users ON users.id = posts.user_id
+		WHERE posts.room_id = $1 AND (posts.timestamp, posts.id) > ($2, $3)
+		ORDER BY posts.timestamp ASC, posts.id ASC LIMIT $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, roomID, after.Timestamp, after.ID, limit)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("error querying the posts table: %s", err))
+	}
+	defer rows.Close()
+
+	return scanPosts(rows)
+}
+
+// scanPosts reads the posts and their associated user data from the rows
+func scanPosts(rows *sql.Rows) ([]*model.Post, error) {
 	var posts []*model.Post
 
 	for rows.Next() {
 		post := &model.Post{
 			User: &model.User{},
 		}
-		if err := rows.Scan(&post.ID, &post.UserID, &post.Message, &post.Timestamp, &post.User.ID, &post.User.Username); err != nil {
+		if err := rows.Scan(&post.ID, &post.UserID, &post.RoomID, &post.Message, &post.Type, &post.Quotes, &post.Timestamp, &post.User.ID, &post.User.Username); err != nil {
 			return nil, errors.New(fmt.Sprintf("error scanning rows: %s", err))
 		}
 		posts = append(posts, post)
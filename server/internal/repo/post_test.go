@@ -22,11 +22,12 @@ func TestCreatePost(t *testing.T) {
 	postID, _ := uuid.FromBytes([]byte("cfab745c-25d2-4a48-a94c-d3f84ef9167a"))
 
 	mock.ExpectQuery("INSERT INTO posts").
-		WithArgs("48ccb5c1-9a19-42cd-bd41-3ac5c8af1108", "Test Message").
-		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(postID))
+		WithArgs("48ccb5c1-9a19-42cd-bd41-3ac5c8af1108", "6b1d0f5e-2a1c-4f0e-9d7a-3c5e8b2f4a10", "Test Message", model.PostTypeChat, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "timestamp"}).AddRow(postID, time.Now()))
 
 	post := &model.Post{
 		UserID:  "48ccb5c1-9a19-42cd-bd41-3ac5c8af1108",
+		RoomID:  "6b1d0f5e-2a1c-4f0e-9d7a-3c5e8b2f4a10",
 		Message: "Test Message",
 	}
 
@@ -35,6 +36,8 @@ func TestCreatePost(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, createdPost)
 	assert.Equal(t, postID, createdPost.ID)
+	assert.Equal(t, model.PostTypeChat, createdPost.Type)
+	assert.NotNil(t, createdPost.Timestamp)
 }
 
 func TestGetRecentPosts(t *testing.T) {
@@ -49,15 +52,49 @@ func TestGetRecentPosts(t *testing.T) {
 	postID, _ := uuid.FromBytes([]byte("cfab745c-25d2-4a48-a94c-d3f84ef9167a"))
 	userID, _ := uuid.FromBytes([]byte("48ccb5c1-9a19-42cd-bd41-3ac5c8af1108"))
 
-	mock.ExpectQuery("SELECT posts.id, posts.user_id, posts.message, posts.timestamp, users.id, users.username").WithArgs(5).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "message", "timestamp", "users_id", "username"}).
-			AddRow(postID, "48ccb5c1-9a19-42cd-bd41-3ac5c8af1108", "Test Message", time.Now(), userID, "Alice"))
+	roomID := "6b1d0f5e-2a1c-4f0e-9d7a-3c5e8b2f4a10"
+
+	mock.ExpectQuery("SELECT posts.id, posts.user_id, posts.room_id, posts.message, posts.type, posts.quotes, posts.timestamp, users.id, users.username").WithArgs(roomID, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "room_id", "message", "type", "quotes", "timestamp", "users_id", "username"}).
+			AddRow(postID, "48ccb5c1-9a19-42cd-bd41-3ac5c8af1108", roomID, "Test Message", model.PostTypeChat, nil, time.Now(), userID, "Alice"))
 
 	limit := 5
-	recentPosts, err := repo.GetRecentPosts(context.Background(), limit)
+	recentPosts, err := repo.GetRecentPosts(context.Background(), roomID, limit)
 
 	// Assert the results
 	assert.NoError(t, err)
 	assert.NotNil(t, recentPosts)
 	assert.Len(t, recentPosts, 1)
 }
+
+func TestGetPostsBefore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open a stub database connection: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewPostRepository(db)
+
+	postID := uuid.MustParse("cfab745c-25d2-4a48-a94c-d3f84ef9167a")
+	userID := uuid.MustParse("48ccb5c1-9a19-42cd-bd41-3ac5c8af1108")
+
+	roomID := "6b1d0f5e-2a1c-4f0e-9d7a-3c5e8b2f4a10"
+	cursor := &model.Cursor{
+		Timestamp: time.Now(),
+		ID:        uuid.MustParse("0b8f4a7e-7c1f-4d43-9a8e-5d2c6f1e3b90"),
+	}
+
+	mock.ExpectQuery("WHERE posts.room_id = \\$1 AND \\(posts.timestamp, posts.id\\) < \\(\\$2, \\$3\\)").
+		WithArgs(roomID, cursor.Timestamp, cursor.ID, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "room_id", "message", "type", "quotes", "timestamp", "users_id", "username"}).
+			AddRow(postID, userID.String(), roomID, "Older Message", model.PostTypeChat, []byte(`[{"symbol":"AAPL.US","close":178.85}]`), cursor.Timestamp.Add(-time.Hour), userID, "Alice"))
+
+	posts, err := repo.GetPostsBefore(context.Background(), roomID, cursor, 5)
+
+	assert.NoError(t, err)
+	assert.Len(t, posts, 1)
+	assert.Equal(t, "Older Message", posts[0].Message)
+	assert.Equal(t, 178.85, posts[0].Quotes[0].Close)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
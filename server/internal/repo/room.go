@@ -0,0 +1,110 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"server/db"
+	"server/internal/model"
+)
+
+var ErrDuplicateRoom = errors.New("a room with that name already exists")
+
+type RoomRepo interface {
+	CreateRoom(ctx context.Context, room *model.Room) (*model.Room, error)
+	GetRooms(ctx context.Context) ([]*model.Room, error)
+	GetRoom(ctx context.Context, roomID string) (*model.Room, error)
+	AddMember(ctx context.Context, roomID string, userID string) error
+	IsMember(ctx context.Context, roomID string, userID string) (bool, error)
+}
+
+type roomRepository struct {
+	db db.DB
+}
+
+// NewRoomRepository builds a roomRepository and injects its dependencies
+func NewRoomRepository(db db.DB) RoomRepo {
+	return &roomRepository{db: db}
+}
+
+// CreateRoom inserts a new room into the database, failing with ErrDuplicateRoom if the name is taken
+func (r *roomRepository) CreateRoom(ctx context.Context, room *model.Room) (*model.Room, error) {
+	query := `INSERT INTO rooms(name, created_by) VALUES ($1, $2) ON CONFLICT (name) DO NOTHING returning id, created_at`
+
+	err := r.db.QueryRowContext(ctx, query, room.Name, room.CreatedBy).Scan(&room.ID, &room.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrDuplicateRoom
+	}
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("error inserting room: %s", err))
+	}
+
+	return room, nil
+}
+
+// GetRooms returns all the rooms sorted by name
+func (r *roomRepository) GetRooms(ctx context.Context) ([]*model.Room, error) {
+	query := `SELECT id, name, created_at FROM rooms ORDER BY name`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("error querying the rooms table: %s", err))
+	}
+	defer rows.Close()
+
+	rooms := make([]*model.Room, 0)
+
+	for rows.Next() {
+		room := &model.Room{}
+		if err := rows.Scan(&room.ID, &room.Name, &room.CreatedAt); err != nil {
+			return nil, errors.New(fmt.Sprintf("error scanning rows: %s", err))
+		}
+		rooms = append(rooms, room)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.New(fmt.Sprintf("error during rows iteration: %s", err))
+	}
+
+	return rooms, nil
+}
+
+// GetRoom searches for a room in the database given its id, returning nil if it does not exist
+func (r *roomRepository) GetRoom(ctx context.Context, roomID string) (*model.Room, error) {
+	room := &model.Room{}
+	query := `SELECT id, name, created_at FROM rooms WHERE id = $1`
+
+	err := r.db.QueryRowContext(ctx, query, roomID).Scan(&room.ID, &room.Name, &room.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("error querying the rooms table: %s", err))
+	}
+
+	return room, nil
+}
+
+// AddMember makes the user a member of the room, it is a no-op if the user already joined it
+func (r *roomRepository) AddMember(ctx context.Context, roomID string, userID string) error {
+	query := `INSERT INTO room_members(room_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+
+	if _, err := r.db.ExecContext(ctx, query, roomID, userID); err != nil {
+		return errors.New(fmt.Sprintf("error inserting room member: %s", err))
+	}
+
+	return nil
+}
+
+// IsMember reports whether the user joined the room
+func (r *roomRepository) IsMember(ctx context.Context, roomID string, userID string) (bool, error) {
+	var member bool
+	query := `SELECT EXISTS(SELECT 1 FROM room_members WHERE room_id = $1 AND user_id = $2)`
+
+	if err := r.db.QueryRowContext(ctx, query, roomID, userID).Scan(&member); err != nil {
+		return false, errors.New(fmt.Sprintf("error querying the room_members table: %s", err))
+	}
+
+	return member, nil
+}
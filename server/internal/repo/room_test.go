@@ -0,0 +1,80 @@
+package repo
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"server/internal/model"
+	"testing"
+	"time"
+)
+
+func TestCreateRoom(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open a stub database connection: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRoomRepository(db)
+
+	roomID := uuid.New()
+
+	mock.ExpectQuery("INSERT INTO rooms").
+		WithArgs("traders", "48ccb5c1-9a19-42cd-bd41-3ac5c8af1108").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(roomID, time.Now()))
+
+	room := &model.Room{
+		Name:      "traders",
+		CreatedBy: "48ccb5c1-9a19-42cd-bd41-3ac5c8af1108",
+	}
+
+	createdRoom, err := repo.CreateRoom(context.Background(), room)
+
+	assert.NoError(t, err)
+	assert.Equal(t, roomID, createdRoom.ID)
+}
+
+func TestCreateRoomDuplicate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open a stub database connection: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRoomRepository(db)
+
+	mock.ExpectQuery("INSERT INTO rooms").
+		WithArgs("general", "48ccb5c1-9a19-42cd-bd41-3ac5c8af1108").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}))
+
+	room := &model.Room{
+		Name:      "general",
+		CreatedBy: "48ccb5c1-9a19-42cd-bd41-3ac5c8af1108",
+	}
+
+	createdRoom, err := repo.CreateRoom(context.Background(), room)
+
+	assert.ErrorIs(t, err, ErrDuplicateRoom)
+	assert.Nil(t, createdRoom)
+}
+
+func TestIsMember(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open a stub database connection: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRoomRepository(db)
+
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs("6b1d0f5e-2a1c-4f0e-9d7a-3c5e8b2f4a10", "f1c21d1d-3411-4bfd-a99f-8fc52dc65bb5").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	member, err := repo.IsMember(context.Background(), "6b1d0f5e-2a1c-4f0e-9d7a-3c5e8b2f4a10", "f1c21d1d-3411-4bfd-a99f-8fc52dc65bb5")
+
+	assert.NoError(t, err)
+	assert.True(t, member)
+}
@@ -0,0 +1,108 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"server/db"
+)
+
+var ErrWatchlistFull = errors.New("the watchlist is full")
+
+type WatchlistRepo interface {
+	GetSymbols(ctx context.Context, userID string) ([]string, error)
+	AddSymbol(ctx context.Context, userID string, symbol string, limit int) (bool, error)
+	RemoveSymbol(ctx context.Context, userID string, symbol string) (bool, error)
+}
+
+type watchlistRepository struct {
+	db db.DB
+}
+
+// NewWatchlistRepository builds a watchlistRepository and injects its dependencies
+func NewWatchlistRepository(db db.DB) WatchlistRepo {
+	return &watchlistRepository{db: db}
+}
+
+// GetSymbols returns the stock codes watched by a user, oldest first
+func (r *watchlistRepository) GetSymbols(ctx context.Context, userID string) ([]string, error) {
+	query := `SELECT symbol FROM watchlists WHERE user_id = $1 ORDER BY created_at, symbol`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("error querying the watchlists table: %s", err))
+	}
+	defer rows.Close()
+
+	symbols := []string{}
+	for rows.Next() {
+		var symbol string
+		if err := rows.Scan(&symbol); err != nil {
+			return nil, errors.New(fmt.Sprintf("error scanning rows: %s", err))
+		}
+		symbols = append(symbols, symbol)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.New(fmt.Sprintf("error during rows iteration: %s", err))
+	}
+
+	return symbols, nil
+}
+
+// AddSymbol adds a stock code to the watchlist of a user, returning false if it was already watched
+// and ErrWatchlistFull if the user already watches limit codes
+func (r *watchlistRepository) AddSymbol(ctx context.Context, userID string, symbol string, limit int) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, errors.New(fmt.Sprintf("error starting the transaction: %s", err))
+	}
+	defer tx.Rollback()
+
+	// locking the user serializes the additions to its watchlist, so concurrent ones cannot exceed the limit
+	var id string
+	if err := tx.QueryRowContext(ctx, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&id); err != nil {
+		return false, errors.New(fmt.Sprintf("error locking the user: %s", err))
+	}
+
+	var count int
+	var watched bool
+	query := `SELECT count(*), count(*) FILTER (WHERE symbol = $2) > 0 FROM watchlists WHERE user_id = $1`
+	if err := tx.QueryRowContext(ctx, query, userID, symbol).Scan(&count, &watched); err != nil {
+		return false, errors.New(fmt.Sprintf("error counting watchlist symbols: %s", err))
+	}
+
+	if watched {
+		return false, nil
+	}
+	if count >= limit {
+		return false, ErrWatchlistFull
+	}
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO watchlists(user_id, symbol) VALUES ($1, $2)`, userID, symbol); err != nil {
+		return false, errors.New(fmt.Sprintf("error inserting watchlist symbol: %s", err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, errors.New(fmt.Sprintf("error committing the transaction: %s", err))
+	}
+
+	return true, nil
+}
+
+// RemoveSymbol removes a stock code from the watchlist of a user, returning false if it was not watched
+func (r *watchlistRepository) RemoveSymbol(ctx context.Context, userID string, symbol string) (bool, error) {
+	query := `DELETE FROM watchlists WHERE user_id = $1 AND symbol = $2`
+
+	res, err := r.db.ExecContext(ctx, query, userID, symbol)
+	if err != nil {
+		return false, errors.New(fmt.Sprintf("error deleting watchlist symbol: %s", err))
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, errors.New(fmt.Sprintf("error deleting watchlist symbol: %s", err))
+	}
+
+	return n > 0, nil
+}
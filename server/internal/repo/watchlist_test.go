@@ -0,0 +1,70 @@
+package repo
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAddSymbol(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open a stub database connection: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWatchlistRepository(db)
+	userID := "f1c21d1d-3411-4bfd-a99f-8fc52dc65bb5"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM users WHERE id = \\$1 FOR UPDATE").WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(userID))
+	mock.ExpectQuery("SELECT count").WithArgs(userID, "msft.us").
+		WillReturnRows(sqlmock.NewRows([]string{"count", "watched"}).AddRow(1, false))
+	mock.ExpectExec("INSERT INTO watchlists").WithArgs(userID, "msft.us").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	added, err := repo.AddSymbol(context.Background(), userID, "msft.us", 2)
+	assert.NoError(t, err)
+	assert.True(t, added)
+
+	// watching a stock twice has no effect
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM users").WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(userID))
+	mock.ExpectQuery("SELECT count").WithArgs(userID, "msft.us").
+		WillReturnRows(sqlmock.NewRows([]string{"count", "watched"}).AddRow(2, true))
+	mock.ExpectRollback()
+
+	added, err = repo.AddSymbol(context.Background(), userID, "msft.us", 2)
+	assert.NoError(t, err)
+	assert.False(t, added)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddSymbolToAFullWatchlist(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open a stub database connection: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWatchlistRepository(db)
+	userID := "f1c21d1d-3411-4bfd-a99f-8fc52dc65bb5"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM users").WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(userID))
+	mock.ExpectQuery("SELECT count").WithArgs(userID, "tsla.us").
+		WillReturnRows(sqlmock.NewRows([]string{"count", "watched"}).AddRow(2, false))
+	mock.ExpectRollback()
+
+	added, err := repo.AddSymbol(context.Background(), userID, "tsla.us", 2)
+	assert.ErrorIs(t, err, ErrWatchlistFull)
+	assert.False(t, added)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"server/internal/model"
+	"strconv"
+	"strings"
+)
+
+// maxAlerts is the number of active alerts a user can have across every room
+const maxAlerts = 20
+
+// alertCondition is a stock code, an operator and a threshold, such as aapl.us>200 or sap.de<120.5
+var alertCondition = regexp.MustCompile(`^([a-z0-9.^_-]+)\s*([<>])\s*([0-9]+(?:\.[0-9]+)?)$`)
+
+// alertCommand creates an alert that notifies the requester when a stock crosses a threshold
+type alertCommand struct {
+	service *commandService
+}
+
+func (c *alertCommand) Name() string  { return "alert" }
+func (c *alertCommand) Usage() string { return "/alert=aapl.us>200" }
+func (c *alertCommand) Help() string {
+	return "mentions you in the room when a stock goes above (>) or below (<) a price, such as /alert=aapl.us<150"
+}
+func (c *alertCommand) Remote() bool { return false }
+
+// Parse expects a stock code, > or < and a positive threshold, returned as <code><operator><threshold>
+func (c *alertCommand) Parse(args string) (string, error) {
+	m := alertCondition.FindStringSubmatch(strings.ToLower(strings.TrimSpace(args)))
+	if m == nil {
+		return "", errors.New("expected a stock code, > or < and a price")
+	}
+
+	threshold, err := strconv.ParseFloat(m[3], 64)
+	if err != nil || threshold <= 0 {
+		return "", errors.New("expected a positive price")
+	}
+
+	return fmt.Sprintf("%s%s%s", m[1], m[2], m[3]), nil
+}
+
+// Handle stores the alert, the bot checks it periodically until it triggers
+func (c *alertCommand) Handle(ctx context.Context, req *CommandRequest) (string, error) {
+	m := alertCondition.FindStringSubmatch(req.Args)
+	threshold, _ := strconv.ParseFloat(m[3], 64)
+
+	count, err := c.service.AlertRepo.CountAlerts(ctx, req.Post.UserID)
+	if err != nil {
+		return "", err
+	}
+	if count >= maxAlerts {
+		return fmt.Sprintf("You already have %d active alerts, remove some of them with /unalert before creating new ones", count), nil
+	}
+
+	alert, err := c.service.AlertRepo.CreateAlert(ctx, &model.Alert{
+		UserID:    req.Post.UserID,
+		RoomID:    req.Post.RoomID,
+		Symbol:    m[1],
+		Operator:  m[2],
+		Threshold: threshold,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Alert #%d created: %s", alert.ID, alert), nil
+}
+
+// alertsCommand lists the active alerts of the requester in the room
+type alertsCommand struct {
+	service *commandService
+}
+
+func (c *alertsCommand) Name() string  { return "alerts" }
+func (c *alertsCommand) Usage() string { return "/alerts" }
+func (c *alertsCommand) Help() string  { return "lists your active alerts in this room" }
+func (c *alertsCommand) Remote() bool  { return false }
+
+// Parse accepts no arguments
+func (c *alertsCommand) Parse(args string) (string, error) {
+	if args != "" {
+		return "", errors.New("alerts takes no arguments")
+	}
+
+	return "", nil
+}
+
+// Handle replies with the id and condition of every active alert
+func (c *alertsCommand) Handle(ctx context.Context, req *CommandRequest) (string, error) {
+	alerts, err := c.service.AlertRepo.GetAlerts(ctx, req.Post.UserID, req.Post.RoomID)
+	if err != nil {
+		return "", err
+	}
+
+	if len(alerts) == 0 {
+		return "You have no active alerts in this room, create one with /alert=aapl.us>200", nil
+	}
+
+	lines := []string{"Your active alerts:"}
+	for _, a := range alerts {
+		lines = append(lines, fmt.Sprintf("#%d %s", a.ID, a))
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// unalertCommand removes an alert of the requester
+type unalertCommand struct {
+	service *commandService
+}
+
+func (c *unalertCommand) Name() string  { return "unalert" }
+func (c *unalertCommand) Usage() string { return "/unalert=1" }
+func (c *unalertCommand) Help() string {
+	return "removes one of your alerts by the id listed by /alerts"
+}
+func (c *unalertCommand) Remote() bool { return false }
+
+// Parse expects the id of an alert
+func (c *unalertCommand) Parse(args string) (string, error) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(strings.TrimSpace(args), "#"), 10, 64)
+	if err != nil || id <= 0 {
+		return "", errors.New("expected an alert id")
+	}
+
+	return strconv.FormatInt(id, 10), nil
+}
+
+// Handle deletes the alert, only if it belongs to the requester
+func (c *unalertCommand) Handle(ctx context.Context, req *CommandRequest) (string, error) {
+	id, _ := strconv.ParseInt(req.Args, 10, 64)
+
+	deleted, err := c.service.AlertRepo.DeleteAlert(ctx, id, req.Post.UserID)
+	if err != nil {
+		return "", err
+	}
+	if !deleted {
+		return fmt.Sprintf("You have no alert #%d, type /alerts to list them", id), nil
+	}
+
+	return fmt.Sprintf("Alert #%d removed", id), nil
+}
@@ -1,135 +1,362 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
+	"github.com/streadway/amqp"
 	"log"
 	"server/internal/infra"
 	"server/internal/model"
 	"server/internal/repo"
 	"strings"
+	"sync"
 	"time"
 )
 
 type CommmandService interface {
-	ProcessCommand(command string)
-	BroadcastCommand(broadcast chan []byte)
-	ParseCommand(command string) (string, error)
+	IsCommand(message string) bool
+	ExecuteCommand(ctx context.Context, post *model.Post) *model.Post
+	BroadcastCommand(broadcast chan *model.Broadcast)
+	BroadcastAlerts(broadcast chan *model.Broadcast)
+	SearchSymbols(ctx context.Context, query string) ([]*model.Symbol, error)
 }
 
 type commandService struct {
 	PostRepo   repo.PostRepo
+	AlertRepo  repo.AlertRepo
+	Watchlist  WatchlistService
 	AMQPClient infra.AMQPClient
+	Registry   *CommandRegistry
+
+	// pending tracks the requests waiting for a quote, by correlation id
+	mu      sync.Mutex
+	pending map[string]*stockPayload
 }
 
+// stockPayload is the envelope of a stock request, the bot copies its correlation id into the reply
 type stockPayload struct {
-	StockCode string `json:"stockCode"`
+	CorrelationID string     `json:"correlationID"`
+	RequesterID   string     `json:"requesterID"`
+	RoomID        string     `json:"roomID"`
+	StockCodes    []string   `json:"stockCodes"`
+	Range         string     `json:"range,omitempty"`
+	Query         string     `json:"query,omitempty"`
+	Timestamp     *time.Time `json:"timestamp"`
+
+	// reply receives the answer of the requests waiting for it, instead of posting it to the room
+	reply chan *quotePayload
 }
 
+// quotePayload is the reply of the bot, StockQuote is the formatted reply and Quotes the structured quotes
+// the posts of the bot not answering a request, such as the digests, have no correlation id and carry their room
 type quotePayload struct {
-	StockQuote string `json:"stockQuote"`
+	CorrelationID string          `json:"correlationID"`
+	RoomID        string          `json:"roomID,omitempty"`
+	StockQuote    string          `json:"stockQuote"`
+	Quotes        model.Quotes    `json:"quotes,omitempty"`
+	Symbols       []*model.Symbol `json:"symbols,omitempty"`
 }
 
 const (
-	userID       = "48ccb5c1-9a19-42cd-bd41-3ac5c8af1108"
-	username     = "StockBot"
-	stockCommand = "/stock="
+	userID         = "48ccb5c1-9a19-42cd-bd41-3ac5c8af1108"
+	username       = "StockBot"
+	commandPrefix  = "/"
+	pendingTimeout = time.Minute
+	searchTimeout  = 5 * time.Second
+	// requestTimeout bounds the wait for rabbitmq when publishing a request to the bot
+	requestTimeout = 5 * time.Second
 )
 
-var commands []*model.Post
+// ErrBotTimeout is returned when the bot does not answer a request in time
+var ErrBotTimeout = errors.New("the bot did not answer in time")
 
-// NewCommandService builds a service and injects its dependencies
-func NewCommandService(postRepo repo.PostRepo, amqpClient infra.AMQPClient) CommmandService {
-	return &commandService{
+// NewCommandService builds a service and injects its dependencies, registering the built-in commands
+func NewCommandService(postRepo repo.PostRepo, alertRepo repo.AlertRepo, watchlist WatchlistService, amqpClient infra.AMQPClient) CommmandService {
+	s := &commandService{
 		PostRepo:   postRepo,
+		AlertRepo:  alertRepo,
+		Watchlist:  watchlist,
 		AMQPClient: amqpClient,
+		Registry:   NewCommandRegistry(),
+		pending:    make(map[string]*stockPayload),
 	}
+
+	s.Registry.Register(&helpCommand{registry: s.Registry})
+	s.Registry.Register(&stockCommand{service: s})
+	s.Registry.Register(&historyCommand{service: s})
+	s.Registry.Register(&chartCommand{service: s})
+	s.Registry.Register(&searchCommand{service: s})
+	s.Registry.Register(&fxCommand{service: s})
+	s.Registry.Register(&cryptoCommand{service: s})
+	s.Registry.Register(&alertCommand{service: s})
+	s.Registry.Register(&alertsCommand{service: s})
+	s.Registry.Register(&unalertCommand{service: s})
+	s.Registry.Register(&watchCommand{service: s})
+	s.Registry.Register(&unwatchCommand{service: s})
+	s.Registry.Register(&watchlistCommand{service: s})
+
+	return s
+}
+
+// IsCommand reports whether the message is a command rather than a chat message
+func (s *commandService) IsCommand(message string) bool {
+	return strings.HasPrefix(strings.TrimSpace(message), commandPrefix)
 }
 
-// ParseCommand extracts the stock code from the command
-// return "" if it is not a stock command, or it is malformed
-func (s *commandService) ParseCommand(command string) (string, error) {
-	if strings.Index(command, stockCommand) != 0 {
-		return "", nil
+// ExecuteCommand runs the command sent in the post and returns the StockBot reply for the requester, if any
+// unknown or malformed commands get an error reply, and commands answered by the bot are handled asynchronously
+func (s *commandService) ExecuteCommand(ctx context.Context, post *model.Post) *model.Post {
+	name, args := splitCommand(post.Message)
+
+	cmd, ok := s.Registry.Lookup(name)
+	if !ok {
+		return newBotPost(post.RoomID, fmt.Sprintf("unknown command: /%s. Type /help to list the available commands", name))
+	}
+
+	parsed, err := cmd.Parse(args)
+	if err != nil {
+		log.Printf("error parsing the command: %s", err)
+		return newBotPost(post.RoomID, fmt.Sprintf("invalid command: %s. It should be something like %s", post.Message, cmd.Usage()))
 	}
 
-	tokens := strings.SplitAfter(command, "=")
-	if len(tokens) != 2 {
-		return "", errors.New(fmt.Sprintf("invalid command: %s. It should be something like /stock=aapl.us", command))
+	req := &CommandRequest{
+		Args: parsed,
+		Post: post,
 	}
 
-	return tokens[1], nil
+	reply, err := cmd.Handle(ctx, req)
+	if err != nil {
+		log.Printf("error handling command %s: %s", cmd.Name(), err)
+		return newBotPost(post.RoomID, fmt.Sprintf("failed to run the command /%s, try again later", cmd.Name()))
+	}
+	if reply == "" {
+		return nil
+	}
+
+	return newBotPost(post.RoomID, reply)
 }
 
-// ProcessCommand processes the command, publishing it to the rabbitmq exchange <stockchat>
-func (s *commandService) ProcessCommand(stockCode string) {
-	log.Println("Processing command for: ", stockCode)
+// requestBot publishes a request to the rabbitmq exchange <stockchat> with the routing key of its kind
+// the request is kept as pending until its answer is received, so it can be answered in the room that requested it
+// publishing waits for rabbitmq at most requestTimeout, so the requester is told when it fails
+func (s *commandService) requestBot(ctx context.Context, routingKey string, pl *stockPayload, post *model.Post) error {
+	log.Println("Processing command for: ", strings.Join(pl.StockCodes, ", "))
 
-	pl := stockPayload{
-		StockCode: stockCode,
-	}
+	pl.RequesterID = post.UserID
+	pl.RoomID = post.RoomID
 
-	body, err := json.Marshal(pl)
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	return s.publishRequest(ctx, routingKey, pl)
+}
+
+// SearchSymbols asks the bot for the symbols of its catalog matching the query and waits for its answer
+func (s *commandService) SearchSymbols(ctx context.Context, query string) ([]*model.Symbol, error) {
+	query, err := parseQuery(query)
 	if err != nil {
-		log.Printf("error marshaling payload: %s", err)
-		return
+		return nil, err
 	}
 
-	err = s.AMQPClient.SetupAMQExchange()
-	if err != nil {
-		log.Printf("error setting up the amq connection and exchange: %s", err)
+	pl := &stockPayload{
+		Query: query,
+		reply: make(chan *quotePayload, 1),
 	}
 
-	if err := s.AMQPClient.PublishAMQMessage(body); err != nil {
-		log.Printf("error publishing to the exchange: %s", err)
+	ctx, cancel := context.WithTimeout(ctx, searchTimeout)
+	defer cancel()
+
+	if err := s.publishRequest(ctx, infra.SearchKey, pl); err != nil {
+		return nil, err
 	}
 
-	log.Printf("Stock sent: %s\n", body)
+	select {
+	case answer := <-pl.reply:
+		if answer.Symbols == nil {
+			return []*model.Symbol{}, nil
+		}
+		return answer.Symbols, nil
+	case <-ctx.Done():
+		s.removePending(pl.CorrelationID)
+		return nil, ErrBotTimeout
+	}
 }
 
-// BroadcastCommand subscribes to the rabbitmq exchange <stockchat> and broadcasts the new quotes received
-func (s *commandService) BroadcastCommand(broadcast chan []byte) {
-	err := s.AMQPClient.SetupAMQExchange()
-	defer s.AMQPClient.Close()
+// publishRequest publishes a request and keeps it as pending until its answer is received
+func (s *commandService) publishRequest(ctx context.Context, routingKey string, pl *stockPayload) error {
+	ts := time.Now().UTC()
+
+	pl.CorrelationID = uuid.NewString()
+	pl.Timestamp = &ts
 
+	body, err := json.Marshal(pl)
 	if err != nil {
-		log.Printf("error setting up the amq connection and exchange: %s", err)
+		return errors.New(fmt.Sprintf("error marshaling payload: %s", err))
 	}
 
+	s.addPending(pl)
+
+	if err := s.AMQPClient.PublishAMQMessage(ctx, routingKey, body, pl.CorrelationID); err != nil {
+		s.removePending(pl.CorrelationID)
+		return errors.New(fmt.Sprintf("error publishing to the exchange: %s", err))
+	}
+
+	log.Printf("Stock sent: %s\n", body)
+	return nil
+}
+
+// BroadcastCommand subscribes to the rabbitmq exchange <stockchat>, stores the new quotes received as StockBot posts
+// and broadcasts them to the room of the request they answer, or to the room of the scheduled digests posted by the bot
+func (s *commandService) BroadcastCommand(broadcast chan *model.Broadcast) {
 	messages, err := s.AMQPClient.ConsumeAMQMessages()
 	if err != nil {
 		log.Printf("error consuming messages: %s", err)
+		return
+	}
+
+	digests, err := s.AMQPClient.ConsumeDigests()
+	if err != nil {
+		log.Printf("error consuming digests: %s", err)
+		return
 	}
 
+	go s.broadcastQuotes(digests, broadcast)
+	s.broadcastQuotes(messages, broadcast)
+}
+
+// broadcastQuotes stores and broadcasts the quotes received from the bot
+// a quote with a correlation id is posted to the room of its pending request, and a quote without it to the room it carries
+// a quote whose request is no longer pending, as the bot retried it through an outage, is posted to the room it carries if any
+func (s *commandService) broadcastQuotes(messages <-chan amqp.Delivery, broadcast chan *model.Broadcast) {
 	for message := range messages {
 		var pl quotePayload
 		if err := json.Unmarshal(message.Body, &pl); err != nil {
 			log.Printf("error unmarshaling message: %s", err)
+			continue
 		}
 
 		log.Printf("Quote received: %s\n", string(message.Body))
 
-		uID, _ := uuid.FromBytes([]byte(userID))
-		ts := time.Now().UTC()
-
-		post := &model.Post{
-			UserID: userID,
-			User: &model.User{
-				ID:       uID,
-				Username: username,
-			},
-			Message:   pl.StockQuote,
-			Timestamp: &ts,
+		correlationID := message.CorrelationId
+		if correlationID == "" {
+			correlationID = pl.CorrelationID
+		}
+
+		roomID := pl.RoomID
+		if correlationID != "" {
+			request := s.removePending(correlationID)
+			if request == nil && roomID == "" {
+				log.Printf("error routing quote: no pending request for correlation id %q", correlationID)
+				continue
+			}
+			if request != nil && request.reply != nil {
+				request.reply <- &pl
+				continue
+			}
+			if request != nil {
+				roomID = request.RoomID
+			}
+		}
+
+		if roomID == "" {
+			log.Printf("error routing quote: no correlation id nor room")
+			continue
+		}
+
+		post := newBotPost(roomID, pl.StockQuote)
+		post.Quotes = pl.Quotes
+
+		if _, err := s.PostRepo.CreatePost(context.Background(), post); err != nil {
+			log.Printf("error creating quote post: %s", err)
+			continue
+		}
+
+		broadcastEvent(broadcast, roomID, model.EventQuoteReceived, post)
+	}
+}
+
+// BroadcastAlerts subscribes to the alerts triggered by the bot, stores them as StockBot posts mentioning
+// the owner of the alert and broadcasts them to the room of the alert
+// the alerts queue is shared by every server instance, so each alert is posted only once
+func (s *commandService) BroadcastAlerts(broadcast chan *model.Broadcast) {
+	messages, err := s.AMQPClient.ConsumeAlerts()
+	if err != nil {
+		log.Printf("error consuming alerts: %s", err)
+		return
+	}
+
+	for message := range messages {
+		var n model.AlertNotification
+		if err := json.Unmarshal(message.Body, &n); err != nil || n.Alert == nil {
+			log.Printf("error unmarshaling alert: %s", message.Body)
+			continue
+		}
+
+		log.Printf("Alert received: %s\n", string(message.Body))
+
+		post := newBotPost(n.Alert.RoomID, fmt.Sprintf("@%s %s", n.Username, n.Message))
+		if n.Quote != nil {
+			post.Quotes = model.Quotes{n.Quote}
+		}
+
+		if _, err := s.PostRepo.CreatePost(context.Background(), post); err != nil {
+			log.Printf("error creating alert post: %s", err)
+			continue
 		}
 
-		addCommandToMemory(post)
-		broadcastPosts(s.PostRepo, broadcast)
+		broadcastEvent(broadcast, n.Alert.RoomID, model.EventAlertFired, post)
 	}
 }
 
-// addCommandToMemory adds a post to the commands in-memory list
-func addCommandToMemory(post *model.Post) {
-	commands = append([]*model.Post{post}, commands...)
+// addPending registers a request waiting for its quote, it is discarded if the quote does not arrive in time
+func (s *commandService) addPending(pl *stockPayload) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending[pl.CorrelationID] = pl
+
+	time.AfterFunc(pendingTimeout, func() {
+		if s.removePending(pl.CorrelationID) != nil {
+			log.Printf("error waiting for quote: request %s timed out", pl.CorrelationID)
+		}
+	})
+}
+
+// removePending unregisters and returns the request with the given correlation id, or nil if there is none
+func (s *commandService) removePending(correlationID string) *stockPayload {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pl, ok := s.pending[correlationID]
+	if !ok {
+		return nil
+	}
+
+	delete(s.pending, correlationID)
+	return pl
+}
+
+// splitCommand splits a /<name>=<args> command into its name and arguments
+func splitCommand(message string) (string, string) {
+	command := strings.TrimPrefix(strings.TrimSpace(message), commandPrefix)
+
+	name, args, _ := strings.Cut(command, "=")
+	return strings.ToLower(name), args
+}
+
+// newBotPost builds a StockBot post for the room
+func newBotPost(roomID string, message string) *model.Post {
+	return &model.Post{
+		UserID: userID,
+		RoomID: roomID,
+		User: &model.User{
+			ID:       uuid.MustParse(userID),
+			Username: username,
+		},
+		Message: message,
+		Type:    model.PostTypeBot,
+	}
 }
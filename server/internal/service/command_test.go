@@ -6,61 +6,222 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/golang/mock/gomock"
-	"github.com/google/uuid"
 	"github.com/streadway/amqp"
 	"github.com/stretchr/testify/assert"
 	"log"
 	"os"
+	"server/internal/infra"
 	mock_infra "server/internal/infra/mocks"
 	"server/internal/model"
 	mock_repo "server/internal/repo/mocks"
+	mock_service "server/internal/service/mocks"
 	"testing"
 	"time"
 )
 
-func TestProcessCommandSuccess(t *testing.T) {
+const roomID = "6b1d0f5e-2a1c-4f0e-9d7a-3c5e8b2f4a10"
+
+func TestExecuteStockCommandSuccess(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	payload := []byte("{\"stockCode\":\"aapl.us\"}")
-
 	mockAMQP := mock_infra.NewMockAMQPClient(ctrl)
-	mockAMQP.EXPECT().SetupAMQExchange().Return(nil)
-	mockAMQP.EXPECT().PublishAMQMessage(payload).Return(nil)
+	mockAMQP.EXPECT().PublishAMQMessage(gomock.Any(), infra.StockKey, gomock.Any(), gomock.Any()).Return(nil)
 
 	mockPostRepo := &mock_repo.MockPostRepo{}
 
 	scanner, reader, writer := mockLogger(t)
 	defer resetLogger(reader, writer)
 
-	service := NewCommandService(mockPostRepo, mockAMQP)
-	go service.ProcessCommand("/stock=aapl.us")
+	post := &model.Post{
+		UserID:  "f1c21d1d-3411-4bfd-a99f-8fc52dc65bb5",
+		RoomID:  roomID,
+		Message: "/stock=aapl.us",
+	}
+
+	service := NewCommandService(mockPostRepo, &mock_repo.MockAlertRepo{}, &mock_service.MockWatchlistService{}, mockAMQP)
+	assert.True(t, service.IsCommand(post.Message))
+
+	// the quote is answered by the bot, so there is no reply for the requester
+	reply := service.ExecuteCommand(context.Background(), post)
+	assert.Nil(t, reply)
 
 	scanner.Scan() // first log: Processing command ...
 	scanner.Scan() // last log: Stock sent ...
 	got := scanner.Text()
-	msg := "Stock sent: {\"stockCode\":\"aapl.us\"}"
-	assert.Contains(t, got, msg, "Expected to have a valid command such as  \"Stock sent: {\\\"stockCode\\\":\\\"aapl.us\\\"}\"")
+	assert.Contains(t, got, "Stock sent: ", "Expected to have a valid command such as  \"Stock sent: {\\\"stockCodes\\\":[\\\"aapl.us\\\"]}\"")
+	assert.Contains(t, got, "\"stockCodes\":[\"aapl.us\"]")
+	assert.Contains(t, got, fmt.Sprintf("\"requesterID\":\"%s\"", post.UserID))
+	assert.Contains(t, got, fmt.Sprintf("\"roomID\":\"%s\"", roomID))
+
+	// assert the request waits for its quote
+	cs := service.(*commandService)
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	assert.Len(t, cs.pending, 1)
 }
 
-func TestProcessCommandFailure(t *testing.T) {
+func TestExecuteStockCommandPublishFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAMQP := mock_infra.NewMockAMQPClient(ctrl)
+	mockAMQP.EXPECT().PublishAMQMessage(gomock.Any(), infra.StockKey, gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, key string, body []byte, correlationID string) error {
+		// rabbitmq is unreachable, the publish waits until the request times out
+		_, bounded := ctx.Deadline()
+		assert.True(t, bounded, "Expected the request to be published with a deadline")
+		return context.DeadlineExceeded
+	})
+
+	service := NewCommandService(&mock_repo.MockPostRepo{}, &mock_repo.MockAlertRepo{}, &mock_service.MockWatchlistService{}, mockAMQP)
+
+	reply := service.ExecuteCommand(context.Background(), &model.Post{RoomID: roomID, Message: "/stock=aapl.us"})
+
+	if assert.NotNil(t, reply, "Expected the requester to be told the command failed") {
+		assert.Equal(t, roomID, reply.RoomID)
+		assert.Contains(t, reply.Message, "failed to run the command /stock")
+	}
+
+	// the failed request does not wait for a quote
+	cs := service.(*commandService)
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	assert.Empty(t, cs.pending)
+}
+
+func TestExecuteCommandFailure(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockPostRepo := &mock_repo.MockPostRepo{}
 	mockAMQP := mock_infra.NewMockAMQPClient(ctrl)
 
-	scanner, reader, writer := mockLogger(t)
-	defer resetLogger(reader, writer)
+	service := NewCommandService(mockPostRepo, &mock_repo.MockAlertRepo{}, &mock_service.MockWatchlistService{}, mockAMQP)
 
-	service := NewCommandService(mockPostRepo, mockAMQP)
-	go service.ProcessCommand("invalid-command")
+	tests := []struct {
+		name    string
+		message string
+		reply   string
+	}{
+		{
+			name:    "malformed arguments",
+			message: "/stock=aapl.us=msft.us",
+			reply:   "invalid command: /stock=aapl.us=msft.us. It should be something like /stock=aapl.us",
+		},
+		{
+			name:    "unknown command",
+			message: "/quote=aapl.us",
+			reply:   "unknown command: /quote. Type /help to list the available commands",
+		},
+	}
 
-	scanner.Scan() // first log: Processing command ...
-	scanner.Scan() // last log: invalid command ...
-	got := scanner.Text()
-	msg := "invalid command: invalid-command. It should be something like /stock=aapl.us"
-	assert.Contains(t, got, msg, "Expected to have an invalid command")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reply := service.ExecuteCommand(context.Background(), &model.Post{RoomID: roomID, Message: tt.message})
+
+			assert.NotNil(t, reply)
+			assert.Equal(t, tt.reply, reply.Message)
+			assert.Equal(t, username, reply.User.Username)
+			assert.Equal(t, model.PostTypeBot, reply.Type)
+		})
+	}
+}
+
+func TestExecuteHelpCommand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostRepo := &mock_repo.MockPostRepo{}
+	mockAMQP := mock_infra.NewMockAMQPClient(ctrl)
+
+	service := NewCommandService(mockPostRepo, &mock_repo.MockAlertRepo{}, &mock_service.MockWatchlistService{}, mockAMQP)
+	reply := service.ExecuteCommand(context.Background(), &model.Post{RoomID: roomID, Message: "/help"})
+
+	assert.NotNil(t, reply)
+	assert.Contains(t, reply.Message, "/help lists the available commands")
+	assert.Contains(t, reply.Message, "/stock=aapl.us gets the quote of one or more comma separated stocks")
+}
+
+func TestExecuteHistoryCommand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	published := make(chan []byte, 1)
+
+	mockAMQP := mock_infra.NewMockAMQPClient(ctrl)
+	mockAMQP.EXPECT().PublishAMQMessage(gomock.Any(), infra.HistoryKey, gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, key string, body []byte, correlationID string) error {
+		published <- body
+		return nil
+	})
+
+	service := NewCommandService(&mock_repo.MockPostRepo{}, &mock_repo.MockAlertRepo{}, &mock_service.MockWatchlistService{}, mockAMQP)
+
+	reply := service.ExecuteCommand(context.Background(), &model.Post{RoomID: roomID, Message: "/history=AAPL.US,6M"})
+	assert.Nil(t, reply)
+
+	var pl stockPayload
+	if err := json.Unmarshal(<-published, &pl); err != nil {
+		t.Fatalf("Failed to unmarshal payload: %s", err)
+	}
+
+	assert.Equal(t, []string{"aapl.us"}, pl.StockCodes)
+	assert.Equal(t, "6m", pl.Range)
+
+	reply = service.ExecuteCommand(context.Background(), &model.Post{RoomID: roomID, Message: "/history=aapl.us,forever"})
+	assert.Equal(t, "invalid command: /history=aapl.us,forever. It should be something like /history=aapl.us,1m", reply.Message)
+
+	// the range is limited to about ten years
+	reply = service.ExecuteCommand(context.Background(), &model.Post{RoomID: roomID, Message: "/history=aapl.us,999y"})
+	assert.Equal(t, "invalid command: /history=aapl.us,999y. It should be something like /history=aapl.us,1m", reply.Message)
+
+	c := &historyCommand{}
+	args, err := c.Parse("aapl.us,10y")
+	assert.NoError(t, err)
+	assert.Equal(t, "aapl.us,10y", args)
+
+	_, err = c.Parse("aapl.us,121m")
+	assert.EqualError(t, err, "the history range cannot exceed 120m")
+}
+
+func TestParseChartCommand(t *testing.T) {
+	c := &chartCommand{}
+
+	args, err := c.Parse("AAPL.US")
+	assert.NoError(t, err)
+	assert.Equal(t, "aapl.us,30", args)
+
+	args, err = c.Parse("aapl.us, 60")
+	assert.NoError(t, err)
+	assert.Equal(t, "aapl.us,60", args)
+
+	_, err = c.Parse("aapl.us,500")
+	assert.EqualError(t, err, "expected between 2 and 120 sessions")
+}
+
+func TestParseStockCodes(t *testing.T) {
+	codes, err := parseStockCodes("AAPL.US, msft.us,aapl.us,tsla.us")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"aapl.us", "msft.us", "tsla.us"}, codes)
+
+	_, err = parseStockCodes("aapl.us,,msft.us")
+	assert.Error(t, err)
+
+	_, err = parseStockCodes("a,b,c,d,e,f,g,h,i,j,k")
+	assert.EqualError(t, err, "expected at most 10 stock codes")
+}
+
+func TestParseStockCommandCurrencies(t *testing.T) {
+	cmd := &stockCommand{}
+
+	args, err := cmd.Parse("SAP.DE:USD, vod.uk,sap.de:usd,sap.de")
+	assert.NoError(t, err)
+	assert.Equal(t, "sap.de:usd,vod.uk,sap.de", args)
+
+	_, err = cmd.Parse("sap.de:dollars")
+	assert.EqualError(t, err, "invalid currency: \"dollars\"")
+
+	_, err = cmd.Parse("sap.de:usd:eur")
+	assert.Error(t, err)
 }
 
 func TestBroadcastCommandSuccess(t *testing.T) {
@@ -68,76 +229,87 @@ func TestBroadcastCommandSuccess(t *testing.T) {
 	defer ctrl.Finish()
 
 	msg := "AAPL.US quote is $178.85 per share"
+	correlationID := "0b8f4a7e-7c1f-4d43-9a8e-5d2c6f1e3b90"
 
 	messages := make(chan amqp.Delivery)
 	go func() {
-		messages <- amqp.Delivery{Body: []byte(fmt.Sprintf("{\"stockQuote\":\"%s\"}", msg))}
+		// a malformed quote must be discarded, without answering the pending request
+		messages <- amqp.Delivery{
+			CorrelationId: correlationID,
+			Body:          []byte("not json"),
+		}
+		// a quote nobody is waiting for must be discarded
+		messages <- amqp.Delivery{
+			CorrelationId: "unknown",
+			Body:          []byte("{\"correlationID\":\"unknown\",\"stockQuote\":\"Unexpected quote\"}"),
+		}
+		messages <- amqp.Delivery{
+			CorrelationId: correlationID,
+			Body:          []byte(fmt.Sprintf("{\"correlationID\":\"%s\",\"stockQuote\":\"%s\",\"quotes\":[{\"symbol\":\"AAPL.US\",\"open\":177.1,\"close\":178.85}]}", correlationID, msg)),
+		}
 	}()
 
 	mockAMQP := mock_infra.NewMockAMQPClient(ctrl)
-	mockAMQP.EXPECT().SetupAMQExchange().Return(nil)
 	mockAMQP.EXPECT().ConsumeAMQMessages().Return(messages, nil)
+	mockAMQP.EXPECT().ConsumeDigests().Return(make(chan amqp.Delivery), nil)
 
-	uID, _ := uuid.FromBytes([]byte(userID))
-	ts1 := time.Now().Add(time.Hour * -1)
-	ts2 := time.Now().Add(time.Hour)
-
-	posts := []*model.Post{
-		{
-			UserID: userID,
-			User: &model.User{
-				ID:       uID,
-				Username: username,
-			},
-			Message:   "Dummy Quote 1",
-			Timestamp: &ts1,
-		},
-		{
-			UserID: userID,
-			User: &model.User{
-				ID:       uID,
-				Username: username,
-			},
-			Message:   "Dummy Quote 2",
-			Timestamp: &ts2,
-		},
-	}
+	var created *model.Post
 
 	mockPostRepo := mock_repo.NewMockPostRepo(ctrl)
-	mockPostRepo.EXPECT().GetRecentPosts(context.Background(), postsLimit).Return(posts, nil)
+	mockPostRepo.EXPECT().CreatePost(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, post *model.Post) (*model.Post, error) {
+		created = post
+		return post, nil
+	})
 
-	broadcast := make(chan []byte)
+	broadcast := make(chan *model.Broadcast)
 	defer close(broadcast)
 	scanner, reader, writer := mockLogger(t)
 	defer resetLogger(reader, writer)
 
-	service := NewCommandService(mockPostRepo, mockAMQP)
+	service := NewCommandService(mockPostRepo, &mock_repo.MockAlertRepo{}, &mock_service.MockWatchlistService{}, mockAMQP)
+	service.(*commandService).addPending(&stockPayload{
+		CorrelationID: correlationID,
+		RoomID:        roomID,
+		StockCodes:    []string{"aapl.us"},
+	})
 	go service.BroadcastCommand(broadcast)
 
+	scanner.Scan() // error unmarshaling message ...
+	assert.Contains(t, scanner.Text(), "error unmarshaling message")
+
+	scanner.Scan() // Quote received: Unexpected quote
+	scanner.Scan() // error routing quote ...
+	assert.Contains(t, scanner.Text(), "no pending request for correlation id \"unknown\"")
+
 	scanner.Scan()
 	got := scanner.Text()
-	txt := fmt.Sprintf("Quote received: {\"stockQuote\":\"%s\"}", msg)
+	txt := fmt.Sprintf("Quote received: {\"correlationID\":\"%s\",\"stockQuote\":\"%s\"", correlationID, msg)
 
 	assert.Contains(t, got, txt)
 
-	// assert addCommandToMemory(post)
-	assert.True(t, containsMessage(commands, msg), "Expected to find the message in the memory db")
+	// assert only the new quote is broadcast to the room
+	m := <-broadcast
+	assert.Equal(t, roomID, m.RoomID)
 
-	// assert broadcastPosts(s.PostRepo, broadcast)
-	receivedMessages := make([]*model.Post, 0)
-
-	for m := range broadcast {
-		var pl []*model.Post
-		if err := json.Unmarshal(m, &pl); err != nil {
-			t.Errorf("Failed to unmarshal message: %s", err)
-		} else {
-			receivedMessages = append(receivedMessages, pl...)
-			break
-		}
+	var event struct {
+		Type string      `json:"type"`
+		Data *model.Post `json:"data"`
+	}
+	if err := json.Unmarshal(m.Payload, &event); err != nil {
+		t.Fatalf("Failed to unmarshal event: %s", err)
 	}
 
-	fmt.Println("Received messages: ", receivedMessages)
-	assert.True(t, containsMessage(receivedMessages, msg), "Expected to find the message in the broadcast channel")
+	assert.Equal(t, model.EventQuoteReceived, event.Type)
+	assert.Equal(t, msg, event.Data.Message, "Expected to find the message in the broadcast channel")
+
+	// assert the quote is stored as a StockBot post
+	assert.Equal(t, msg, created.Message)
+	assert.Equal(t, userID, created.UserID)
+	assert.Equal(t, roomID, created.RoomID)
+	assert.Equal(t, model.PostTypeBot, created.Type)
+	assert.Len(t, created.Quotes, 1)
+	assert.Equal(t, 178.85, created.Quotes[0].Close)
+	assert.Equal(t, 178.85, event.Data.Quotes[0].Close)
 }
 
 // Util functions
@@ -171,3 +343,177 @@ func containsMessage(posts []*model.Post, message string) bool {
 
 	return false
 }
+
+func TestExecuteAlertCommands(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const requesterID = "f1c21d1d-3411-4bfd-a99f-8fc52dc65bb5"
+
+	mockAlertRepo := mock_repo.NewMockAlertRepo(ctrl)
+	mockAlertRepo.EXPECT().CountAlerts(gomock.Any(), requesterID).Return(0, nil)
+	mockAlertRepo.EXPECT().CreateAlert(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, a *model.Alert) (*model.Alert, error) {
+		assert.Equal(t, "aapl.us", a.Symbol)
+		assert.Equal(t, model.AlertAbove, a.Operator)
+		assert.Equal(t, 200.5, a.Threshold)
+		a.ID = 3
+		return a, nil
+	})
+	mockAlertRepo.EXPECT().GetAlerts(gomock.Any(), requesterID, roomID).
+		Return([]*model.Alert{{ID: 3, Symbol: "aapl.us", Operator: model.AlertAbove, Threshold: 200.5}}, nil)
+	mockAlertRepo.EXPECT().DeleteAlert(gomock.Any(), int64(4), requesterID).Return(false, nil)
+
+	service := NewCommandService(&mock_repo.MockPostRepo{}, mockAlertRepo, &mock_service.MockWatchlistService{}, mock_infra.NewMockAMQPClient(ctrl))
+
+	tests := []struct {
+		message string
+		reply   string
+	}{
+		{message: "/alert=AAPL.US > 200.5", reply: "Alert #3 created: AAPL.US > $200.50"},
+		{message: "/alerts", reply: "Your active alerts:\n#3 AAPL.US > $200.50"},
+		{message: "/unalert=4", reply: "You have no alert #4, type /alerts to list them"},
+		{message: "/alert=aapl.us=200", reply: "invalid command: /alert=aapl.us=200. It should be something like /alert=aapl.us>200"},
+	}
+
+	for _, tt := range tests {
+		reply := service.ExecuteCommand(context.Background(), &model.Post{UserID: requesterID, RoomID: roomID, Message: tt.message})
+
+		assert.NotNil(t, reply, tt.message)
+		assert.Equal(t, tt.reply, reply.Message, tt.message)
+	}
+}
+
+func TestBroadcastDigest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	digests := make(chan amqp.Delivery, 1)
+	digests <- amqp.Delivery{
+		Body: []byte(fmt.Sprintf("{\"roomID\":\"%s\",\"stockQuote\":\"Market open digest\"}", roomID)),
+	}
+
+	mockAMQP := mock_infra.NewMockAMQPClient(ctrl)
+	mockAMQP.EXPECT().ConsumeAMQMessages().Return(make(chan amqp.Delivery), nil)
+	mockAMQP.EXPECT().ConsumeDigests().Return(digests, nil)
+
+	mockPostRepo := mock_repo.NewMockPostRepo(ctrl)
+	mockPostRepo.EXPECT().CreatePost(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, post *model.Post) (*model.Post, error) {
+		return post, nil
+	})
+
+	broadcast := make(chan *model.Broadcast)
+
+	service := NewCommandService(mockPostRepo, &mock_repo.MockAlertRepo{}, &mock_service.MockWatchlistService{}, mockAMQP)
+	go service.BroadcastCommand(broadcast)
+
+	// the digest answers no request, so it is posted to the room it carries
+	m := <-broadcast
+	assert.Equal(t, roomID, m.RoomID)
+	assert.Contains(t, string(m.Payload), "Market open digest")
+}
+
+func TestBroadcastLateQuoteToItsRoom(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	msg := "The quote provider is unavailable, AAPL.US could not be fetched. Please try again later"
+
+	// the request timed out before the bot answered it after its retries
+	messages := make(chan amqp.Delivery, 1)
+	messages <- amqp.Delivery{
+		CorrelationId: "expired",
+		Body:          []byte(fmt.Sprintf(`{"correlationID":"expired","roomID":"%s","stockQuote":"%s"}`, roomID, msg)),
+	}
+	close(messages)
+
+	mockAMQP := mock_infra.NewMockAMQPClient(ctrl)
+	mockAMQP.EXPECT().ConsumeAMQMessages().Return(messages, nil)
+	mockAMQP.EXPECT().ConsumeDigests().Return(make(chan amqp.Delivery), nil)
+
+	mockPostRepo := mock_repo.NewMockPostRepo(ctrl)
+	mockPostRepo.EXPECT().CreatePost(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, post *model.Post) (*model.Post, error) {
+		return post, nil
+	})
+
+	broadcast := make(chan *model.Broadcast, 1)
+
+	service := NewCommandService(mockPostRepo, &mock_repo.MockAlertRepo{}, &mock_service.MockWatchlistService{}, mockAMQP)
+	service.BroadcastCommand(broadcast)
+
+	m := <-broadcast
+	assert.Equal(t, roomID, m.RoomID)
+	assert.Contains(t, string(m.Payload), msg)
+}
+
+func TestSearchSymbols(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	messages := make(chan amqp.Delivery, 1)
+
+	mockAMQP := mock_infra.NewMockAMQPClient(ctrl)
+	mockAMQP.EXPECT().ConsumeAMQMessages().Return(messages, nil)
+	mockAMQP.EXPECT().ConsumeDigests().Return(make(chan amqp.Delivery), nil)
+	mockAMQP.EXPECT().PublishAMQMessage(gomock.Any(), infra.SearchKey, gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, key string, body []byte, correlationID string) error {
+		assert.Contains(t, string(body), `"query":"apple inc"`)
+
+		// the bot answers with the matching symbols, which are not posted to any room
+		messages <- amqp.Delivery{
+			CorrelationId: correlationID,
+			Body:          []byte(`{"stockQuote":"Symbols matching","symbols":[{"code":"aapl.us","name":"Apple Inc.","exchange":"NASDAQ"}]}`),
+		}
+		return nil
+	})
+
+	service := NewCommandService(&mock_repo.MockPostRepo{}, &mock_repo.MockAlertRepo{}, &mock_service.MockWatchlistService{}, mockAMQP)
+	go service.BroadcastCommand(make(chan *model.Broadcast))
+
+	symbols, err := service.SearchSymbols(context.Background(), "  apple   inc ")
+	assert.NoError(t, err)
+	assert.Equal(t, []*model.Symbol{{Code: "aapl.us", Name: "Apple Inc.", Exchange: "NASDAQ"}}, symbols)
+
+	_, err = service.SearchSymbols(context.Background(), " ")
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
+
+func TestSearchSymbolsTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAMQP := mock_infra.NewMockAMQPClient(ctrl)
+	mockAMQP.EXPECT().PublishAMQMessage(gomock.Any(), infra.SearchKey, gomock.Any(), gomock.Any()).Return(nil)
+
+	service := NewCommandService(&mock_repo.MockPostRepo{}, &mock_repo.MockAlertRepo{}, &mock_service.MockWatchlistService{}, mockAMQP)
+
+	// the bot never answers, a shorter deadline than the search timeout spares the wait
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := service.SearchSymbols(ctx, "apple")
+	assert.ErrorIs(t, err, ErrBotTimeout)
+	assert.Empty(t, service.(*commandService).pending, "Expected the unanswered search to stop being pending")
+}
+
+func TestParsePairCommands(t *testing.T) {
+	fx := &fxCommand{}
+
+	args, err := fx.Parse(" EUR/USD ")
+	assert.NoError(t, err)
+	assert.Equal(t, "eurusd", args)
+
+	_, err = fx.Parse("euro")
+	assert.Error(t, err)
+
+	crypto := &cryptoCommand{}
+
+	args, err = crypto.Parse("BTC")
+	assert.NoError(t, err)
+	assert.Equal(t, "btc:usd", args)
+
+	args, err = crypto.Parse("eth:EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, "eth:eur", args)
+
+	_, err = crypto.Parse("btc:dollars")
+	assert.Error(t, err)
+}
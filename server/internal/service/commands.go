@@ -0,0 +1,346 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"server/internal/infra"
+	"strconv"
+	"strings"
+)
+
+// helpCommand lists the available commands
+type helpCommand struct {
+	registry *CommandRegistry
+}
+
+func (c *helpCommand) Name() string  { return "help" }
+func (c *helpCommand) Usage() string { return "/help" }
+func (c *helpCommand) Help() string  { return "lists the available commands" }
+func (c *helpCommand) Remote() bool  { return false }
+
+// Parse accepts no arguments
+func (c *helpCommand) Parse(args string) (string, error) {
+	if args != "" {
+		return "", errors.New("help takes no arguments")
+	}
+
+	return "", nil
+}
+
+// Handle replies with the usage and description of every command
+func (c *helpCommand) Handle(ctx context.Context, req *CommandRequest) (string, error) {
+	lines := []string{"Available commands:"}
+	for _, cmd := range c.registry.Commands() {
+		lines = append(lines, fmt.Sprintf("%s %s", cmd.Usage(), cmd.Help()))
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// stockCommand requests the quotes of one or more stocks to the bot
+type stockCommand struct {
+	service *commandService
+}
+
+const maxStockCodes = 10
+
+// currencyCode is the iso code of the currency the quotes are converted to, such as usd
+var currencyCode = regexp.MustCompile(`^[a-z]{3}$`)
+
+func (c *stockCommand) Name() string  { return "stock" }
+func (c *stockCommand) Usage() string { return "/stock=aapl.us" }
+func (c *stockCommand) Help() string {
+	return "gets the quote of one or more comma separated stocks, such as /stock=aapl.us,msft.us, or in another currency such as /stock=sap.de:usd"
+}
+func (c *stockCommand) Remote() bool { return true }
+
+// Parse expects one or more comma separated stock codes, each one with an optional target currency such as sap.de:usd
+// they are returned in lower case and without duplicates
+func (c *stockCommand) Parse(args string) (string, error) {
+	var codes []string
+	seen := make(map[string]bool)
+
+	for _, arg := range strings.Split(args, ",") {
+		code, target, found := strings.Cut(arg, ":")
+
+		parsed, err := parseStockCodes(code)
+		if err != nil {
+			return "", err
+		}
+
+		code = parsed[0]
+		if found {
+			target = strings.ToLower(strings.TrimSpace(target))
+			if !currencyCode.MatchString(target) {
+				return "", errors.New(fmt.Sprintf("invalid currency: %q", target))
+			}
+			code = fmt.Sprintf("%s:%s", code, target)
+		}
+
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+
+	if len(codes) > maxStockCodes {
+		return "", errors.New(fmt.Sprintf("expected at most %d stock codes", maxStockCodes))
+	}
+
+	return strings.Join(codes, ","), nil
+}
+
+// Handle publishes the quote request, its quotes are broadcast to the room when the bot answers it
+func (c *stockCommand) Handle(ctx context.Context, req *CommandRequest) (string, error) {
+	pl := &stockPayload{
+		StockCodes: strings.Split(req.Args, ","),
+	}
+
+	return "", c.service.requestBot(ctx, infra.StockKey, pl, req.Post)
+}
+
+// historyCommand requests a summary of the daily history of a stock to the bot
+type historyCommand struct {
+	service *commandService
+}
+
+const defaultHistoryRange = "1m"
+
+// historyRange is a number followed by a unit: d (trading sessions), w (weeks), m (months) or y (years)
+var historyRange = regexp.MustCompile(`^[1-9][0-9]{0,2}[dwmy]$`)
+
+// maxHistoryRange is the longest range of each unit, about ten years
+var maxHistoryRange = map[byte]int{'d': 2520, 'w': 520, 'm': 120, 'y': 10}
+
+func (c *historyCommand) Name() string  { return "history" }
+func (c *historyCommand) Usage() string { return "/history=aapl.us,1m" }
+func (c *historyCommand) Help() string {
+	return "summarizes the daily history of a stock over a range such as 5d, 2w, 6m or 1y, one month by default and up to 10y"
+}
+func (c *historyCommand) Remote() bool { return true }
+
+// Parse expects a stock code and an optional range, returned as <code>,<range>
+func (c *historyCommand) Parse(args string) (string, error) {
+	code, rng, _ := strings.Cut(args, ",")
+
+	codes, err := parseStockCodes(code)
+	if err != nil {
+		return "", err
+	}
+
+	rng = strings.ToLower(strings.TrimSpace(rng))
+	if rng == "" {
+		rng = defaultHistoryRange
+	}
+	if !historyRange.MatchString(rng) {
+		return "", errors.New(fmt.Sprintf("invalid history range: %q", rng))
+	}
+	unit := rng[len(rng)-1]
+	if n, _ := strconv.Atoi(rng[:len(rng)-1]); n > maxHistoryRange[unit] {
+		return "", errors.New(fmt.Sprintf("the history range cannot exceed %d%c", maxHistoryRange[unit], unit))
+	}
+
+	return fmt.Sprintf("%s,%s", codes[0], rng), nil
+}
+
+// Handle publishes the history request, its summary is broadcast to the room when the bot answers it
+func (c *historyCommand) Handle(ctx context.Context, req *CommandRequest) (string, error) {
+	code, rng, _ := strings.Cut(req.Args, ",")
+
+	pl := &stockPayload{
+		StockCodes: []string{code},
+		Range:      rng,
+	}
+
+	return "", c.service.requestBot(ctx, infra.HistoryKey, pl, req.Post)
+}
+
+// chartCommand requests a sparkline of the last closes of a stock to the bot
+type chartCommand struct {
+	service *commandService
+}
+
+const (
+	defaultChartSessions = 30
+	maxChartSessions     = 120
+)
+
+func (c *chartCommand) Name() string  { return "chart" }
+func (c *chartCommand) Usage() string { return "/chart=aapl.us" }
+func (c *chartCommand) Help() string {
+	return "charts the last closes of a stock, 30 sessions by default or up to 120 such as /chart=aapl.us,60"
+}
+func (c *chartCommand) Remote() bool { return true }
+
+// Parse expects a stock code and an optional number of sessions, returned as <code>,<sessions>
+func (c *chartCommand) Parse(args string) (string, error) {
+	code, n, _ := strings.Cut(args, ",")
+
+	codes, err := parseStockCodes(code)
+	if err != nil {
+		return "", err
+	}
+
+	sessions := defaultChartSessions
+	if n = strings.TrimSpace(n); n != "" {
+		sessions, err = strconv.Atoi(n)
+		if err != nil || sessions < 2 || sessions > maxChartSessions {
+			return "", errors.New(fmt.Sprintf("expected between 2 and %d sessions", maxChartSessions))
+		}
+	}
+
+	return fmt.Sprintf("%s,%d", codes[0], sessions), nil
+}
+
+// Handle publishes the chart request, the bot answers it with the sparkline of the last sessions
+func (c *chartCommand) Handle(ctx context.Context, req *CommandRequest) (string, error) {
+	code, sessions, _ := strings.Cut(req.Args, ",")
+
+	pl := &stockPayload{
+		StockCodes: []string{code},
+		Range:      sessions + "d",
+	}
+
+	return "", c.service.requestBot(ctx, infra.ChartKey, pl, req.Post)
+}
+
+// fxCommand requests the rate of a currency pair to the bot
+type fxCommand struct {
+	service *commandService
+}
+
+// currencyPair is a pair of iso currency codes, such as eurusd
+var currencyPair = regexp.MustCompile(`^[a-z]{6}$`)
+
+func (c *fxCommand) Name() string  { return "fx" }
+func (c *fxCommand) Usage() string { return "/fx=eurusd" }
+func (c *fxCommand) Help() string {
+	return "gets the exchange rate of a currency pair, such as /fx=eurusd or /fx=usd/jpy"
+}
+func (c *fxCommand) Remote() bool { return true }
+
+// Parse expects a currency pair, optionally separated by a slash, returned in lower case such as eurusd
+func (c *fxCommand) Parse(args string) (string, error) {
+	pair := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(args), "/", ""))
+	if !currencyPair.MatchString(pair) {
+		return "", errors.New(fmt.Sprintf("invalid currency pair: %q", args))
+	}
+
+	return pair, nil
+}
+
+// Handle publishes the rate request, the rate is broadcast to the room when the bot answers it
+func (c *fxCommand) Handle(ctx context.Context, req *CommandRequest) (string, error) {
+	pl := &stockPayload{
+		StockCodes: []string{req.Args},
+	}
+
+	return "", c.service.requestBot(ctx, infra.FXKey, pl, req.Post)
+}
+
+// cryptoCommand requests the price of a cryptocurrency to the bot
+type cryptoCommand struct {
+	service *commandService
+}
+
+// cryptoCode is the ticker of a cryptocurrency, such as btc
+var cryptoCode = regexp.MustCompile(`^[a-z0-9]{2,10}$`)
+
+func (c *cryptoCommand) Name() string  { return "crypto" }
+func (c *cryptoCommand) Usage() string { return "/crypto=btc" }
+func (c *cryptoCommand) Help() string {
+	return "gets the price of a cryptocurrency in dollars, or in another currency such as /crypto=eth:eur"
+}
+func (c *cryptoCommand) Remote() bool { return true }
+
+// Parse expects a cryptocurrency ticker and an optional currency, returned as <ticker>:<currency>
+func (c *cryptoCommand) Parse(args string) (string, error) {
+	code, target, found := strings.Cut(strings.ToLower(strings.TrimSpace(args)), ":")
+	if !cryptoCode.MatchString(code) {
+		return "", errors.New(fmt.Sprintf("invalid cryptocurrency: %q", code))
+	}
+
+	if !found {
+		target = "usd"
+	}
+	if !currencyCode.MatchString(target) {
+		return "", errors.New(fmt.Sprintf("invalid currency: %q", target))
+	}
+
+	return fmt.Sprintf("%s:%s", code, target), nil
+}
+
+// Handle publishes the price request, the price is broadcast to the room when the bot answers it
+func (c *cryptoCommand) Handle(ctx context.Context, req *CommandRequest) (string, error) {
+	pl := &stockPayload{
+		StockCodes: []string{req.Args},
+	}
+
+	return "", c.service.requestBot(ctx, infra.CryptoKey, pl, req.Post)
+}
+
+// searchCommand searches the symbol catalog of the bot by ticker or company name
+type searchCommand struct {
+	service *commandService
+}
+
+const maxQueryLength = 50
+
+var ErrInvalidQuery = errors.New(fmt.Sprintf("expected a search text of up to %d characters", maxQueryLength))
+
+func (c *searchCommand) Name() string  { return "search" }
+func (c *searchCommand) Usage() string { return "/search=apple" }
+func (c *searchCommand) Help() string {
+	return "finds the stock codes matching a ticker or a company name, such as /search=vodafone"
+}
+func (c *searchCommand) Remote() bool { return true }
+
+// Parse expects the text to search
+func (c *searchCommand) Parse(args string) (string, error) {
+	return parseQuery(args)
+}
+
+// Handle publishes the search request, the matching symbols are broadcast to the room when the bot answers it
+func (c *searchCommand) Handle(ctx context.Context, req *CommandRequest) (string, error) {
+	pl := &stockPayload{
+		Query: req.Args,
+	}
+
+	return "", c.service.requestBot(ctx, infra.SearchKey, pl, req.Post)
+}
+
+// parseQuery trims a search text and checks its length
+func parseQuery(query string) (string, error) {
+	query = strings.Join(strings.Fields(query), " ")
+	if query == "" || len(query) > maxQueryLength {
+		return "", ErrInvalidQuery
+	}
+
+	return query, nil
+}
+
+// parseStockCodes splits a list of comma separated stock codes
+func parseStockCodes(args string) ([]string, error) {
+	var codes []string
+	seen := make(map[string]bool)
+
+	for _, code := range strings.Split(args, ",") {
+		code = strings.ToLower(strings.TrimSpace(code))
+		if code == "" || strings.ContainsAny(code, "=: ") {
+			return nil, errors.New("expected a stock code")
+		}
+
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+
+	if len(codes) > maxStockCodes {
+		return nil, errors.New(fmt.Sprintf("expected at most %d stock codes", maxStockCodes))
+	}
+
+	return codes, nil
+}
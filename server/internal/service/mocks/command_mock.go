@@ -0,0 +1,103 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: command.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	reflect "reflect"
+	model "server/internal/model"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockCommmandService is a mock of CommmandService interface.
+type MockCommmandService struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommmandServiceMockRecorder
+}
+
+// MockCommmandServiceMockRecorder is the mock recorder for MockCommmandService.
+type MockCommmandServiceMockRecorder struct {
+	mock *MockCommmandService
+}
+
+// NewMockCommmandService creates a new mock instance.
+func NewMockCommmandService(ctrl *gomock.Controller) *MockCommmandService {
+	mock := &MockCommmandService{ctrl: ctrl}
+	mock.recorder = &MockCommmandServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCommmandService) EXPECT() *MockCommmandServiceMockRecorder {
+	return m.recorder
+}
+
+// BroadcastAlerts mocks base method.
+func (m *MockCommmandService) BroadcastAlerts(broadcast chan *model.Broadcast) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "BroadcastAlerts", broadcast)
+}
+
+// BroadcastAlerts indicates an expected call of BroadcastAlerts.
+func (mr *MockCommmandServiceMockRecorder) BroadcastAlerts(broadcast interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BroadcastAlerts", reflect.TypeOf((*MockCommmandService)(nil).BroadcastAlerts), broadcast)
+}
+
+// BroadcastCommand mocks base method.
+func (m *MockCommmandService) BroadcastCommand(broadcast chan *model.Broadcast) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "BroadcastCommand", broadcast)
+}
+
+// BroadcastCommand indicates an expected call of BroadcastCommand.
+func (mr *MockCommmandServiceMockRecorder) BroadcastCommand(broadcast interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BroadcastCommand", reflect.TypeOf((*MockCommmandService)(nil).BroadcastCommand), broadcast)
+}
+
+// ExecuteCommand mocks base method.
+func (m *MockCommmandService) ExecuteCommand(ctx context.Context, post *model.Post) *model.Post {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteCommand", ctx, post)
+	ret0, _ := ret[0].(*model.Post)
+	return ret0
+}
+
+// ExecuteCommand indicates an expected call of ExecuteCommand.
+func (mr *MockCommmandServiceMockRecorder) ExecuteCommand(ctx, post interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteCommand", reflect.TypeOf((*MockCommmandService)(nil).ExecuteCommand), ctx, post)
+}
+
+// IsCommand mocks base method.
+func (m *MockCommmandService) IsCommand(message string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsCommand", message)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsCommand indicates an expected call of IsCommand.
+func (mr *MockCommmandServiceMockRecorder) IsCommand(message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsCommand", reflect.TypeOf((*MockCommmandService)(nil).IsCommand), message)
+}
+
+// SearchSymbols mocks base method.
+func (m *MockCommmandService) SearchSymbols(ctx context.Context, query string) ([]*model.Symbol, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchSymbols", ctx, query)
+	ret0, _ := ret[0].([]*model.Symbol)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchSymbols indicates an expected call of SearchSymbols.
+func (mr *MockCommmandServiceMockRecorder) SearchSymbols(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchSymbols", reflect.TypeOf((*MockCommmandService)(nil).SearchSymbols), ctx, query)
+}
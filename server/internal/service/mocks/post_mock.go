@@ -36,7 +36,7 @@ func (m *MockPostService) EXPECT() *MockPostServiceMockRecorder {
 }
 
 // CreatePost mocks base method.
-func (m *MockPostService) CreatePost(ctx context.Context, post *model.Post, broadcast chan []byte) error {
+func (m *MockPostService) CreatePost(ctx context.Context, post *model.Post, broadcast chan *model.Broadcast) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreatePost", ctx, post, broadcast)
 	ret0, _ := ret[0].(error)
@@ -48,3 +48,57 @@ func (mr *MockPostServiceMockRecorder) CreatePost(ctx, post, broadcast interface
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePost", reflect.TypeOf((*MockPostService)(nil).CreatePost), ctx, post, broadcast)
 }
+
+// GetHistory mocks base method.
+func (m *MockPostService) GetHistory(ctx context.Context, roomID string, limit int) ([]*model.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistory", ctx, roomID, limit)
+	ret0, _ := ret[0].([]*model.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistory indicates an expected call of GetHistory.
+func (mr *MockPostServiceMockRecorder) GetHistory(ctx, roomID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistory", reflect.TypeOf((*MockPostService)(nil).GetHistory), ctx, roomID, limit)
+}
+
+// GetPosts mocks base method.
+func (m *MockPostService) GetPosts(ctx context.Context, roomID, before, after string, limit int) (*model.PostPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPosts", ctx, roomID, before, after, limit)
+	ret0, _ := ret[0].(*model.PostPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPosts indicates an expected call of GetPosts.
+func (mr *MockPostServiceMockRecorder) GetPosts(ctx, roomID, before, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPosts", reflect.TypeOf((*MockPostService)(nil).GetPosts), ctx, roomID, before, after, limit)
+}
+
+// UserJoined mocks base method.
+func (m *MockPostService) UserJoined(user *model.User, roomID string, broadcast chan *model.Broadcast) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UserJoined", user, roomID, broadcast)
+}
+
+// UserJoined indicates an expected call of UserJoined.
+func (mr *MockPostServiceMockRecorder) UserJoined(user, roomID, broadcast interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserJoined", reflect.TypeOf((*MockPostService)(nil).UserJoined), user, roomID, broadcast)
+}
+
+// UserLeft mocks base method.
+func (m *MockPostService) UserLeft(user *model.User, roomID string, broadcast chan *model.Broadcast) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UserLeft", user, roomID, broadcast)
+}
+
+// UserLeft indicates an expected call of UserLeft.
+func (mr *MockPostServiceMockRecorder) UserLeft(user, roomID, broadcast interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserLeft", reflect.TypeOf((*MockPostService)(nil).UserLeft), user, roomID, broadcast)
+}
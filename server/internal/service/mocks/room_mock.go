@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: room.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	reflect "reflect"
+	model "server/internal/model"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockRoomService is a mock of RoomService interface.
+type MockRoomService struct {
+	ctrl     *gomock.Controller
+	recorder *MockRoomServiceMockRecorder
+}
+
+// MockRoomServiceMockRecorder is the mock recorder for MockRoomService.
+type MockRoomServiceMockRecorder struct {
+	mock *MockRoomService
+}
+
+// NewMockRoomService creates a new mock instance.
+func NewMockRoomService(ctrl *gomock.Controller) *MockRoomService {
+	mock := &MockRoomService{ctrl: ctrl}
+	mock.recorder = &MockRoomServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRoomService) EXPECT() *MockRoomServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateRoom mocks base method.
+func (m *MockRoomService) CreateRoom(ctx context.Context, room *model.Room, user *model.User) (*model.Room, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRoom", ctx, room, user)
+	ret0, _ := ret[0].(*model.Room)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRoom indicates an expected call of CreateRoom.
+func (mr *MockRoomServiceMockRecorder) CreateRoom(ctx, room, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRoom", reflect.TypeOf((*MockRoomService)(nil).CreateRoom), ctx, room, user)
+}
+
+// GetRooms mocks base method.
+func (m *MockRoomService) GetRooms(ctx context.Context) ([]*model.Room, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRooms", ctx)
+	ret0, _ := ret[0].([]*model.Room)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRooms indicates an expected call of GetRooms.
+func (mr *MockRoomServiceMockRecorder) GetRooms(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRooms", reflect.TypeOf((*MockRoomService)(nil).GetRooms), ctx)
+}
+
+// IsMember mocks base method.
+func (m *MockRoomService) IsMember(ctx context.Context, roomID string, user *model.User) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsMember", ctx, roomID, user)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsMember indicates an expected call of IsMember.
+func (mr *MockRoomServiceMockRecorder) IsMember(ctx, roomID, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsMember", reflect.TypeOf((*MockRoomService)(nil).IsMember), ctx, roomID, user)
+}
+
+// JoinRoom mocks base method.
+func (m *MockRoomService) JoinRoom(ctx context.Context, roomID string, user *model.User) (*model.Room, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinRoom", ctx, roomID, user)
+	ret0, _ := ret[0].(*model.Room)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// JoinRoom indicates an expected call of JoinRoom.
+func (mr *MockRoomServiceMockRecorder) JoinRoom(ctx, roomID, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinRoom", reflect.TypeOf((*MockRoomService)(nil).JoinRoom), ctx, roomID, user)
+}
@@ -51,11 +51,12 @@ func (mr *MockUserServiceMockRecorder) CreateUser(ctx, user interface{}) *gomock
 }
 
 // LoginUser mocks base method.
-func (m *MockUserService) LoginUser(ctx context.Context, user *model.User) *model.User {
+func (m *MockUserService) LoginUser(ctx context.Context, user *model.User) (*model.Session, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "LoginUser", ctx, user)
-	ret0, _ := ret[0].(*model.User)
-	return ret0
+	ret0, _ := ret[0].(*model.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // LoginUser indicates an expected call of LoginUser.
@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: watchlist.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	reflect "reflect"
+	model "server/internal/model"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockWatchlistService is a mock of WatchlistService interface.
+type MockWatchlistService struct {
+	ctrl     *gomock.Controller
+	recorder *MockWatchlistServiceMockRecorder
+}
+
+// MockWatchlistServiceMockRecorder is the mock recorder for MockWatchlistService.
+type MockWatchlistServiceMockRecorder struct {
+	mock *MockWatchlistService
+}
+
+// NewMockWatchlistService creates a new mock instance.
+func NewMockWatchlistService(ctrl *gomock.Controller) *MockWatchlistService {
+	mock := &MockWatchlistService{ctrl: ctrl}
+	mock.recorder = &MockWatchlistServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWatchlistService) EXPECT() *MockWatchlistServiceMockRecorder {
+	return m.recorder
+}
+
+// GetWatchlist mocks base method.
+func (m *MockWatchlistService) GetWatchlist(ctx context.Context, user *model.User) (*model.Watchlist, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWatchlist", ctx, user)
+	ret0, _ := ret[0].(*model.Watchlist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWatchlist indicates an expected call of GetWatchlist.
+func (mr *MockWatchlistServiceMockRecorder) GetWatchlist(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWatchlist", reflect.TypeOf((*MockWatchlistService)(nil).GetWatchlist), ctx, user)
+}
+
+// Unwatch mocks base method.
+func (m *MockWatchlistService) Unwatch(ctx context.Context, user *model.User, symbol string) (*model.Watchlist, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unwatch", ctx, user, symbol)
+	ret0, _ := ret[0].(*model.Watchlist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unwatch indicates an expected call of Unwatch.
+func (mr *MockWatchlistServiceMockRecorder) Unwatch(ctx, user, symbol interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unwatch", reflect.TypeOf((*MockWatchlistService)(nil).Unwatch), ctx, user, symbol)
+}
+
+// Watch mocks base method.
+func (m *MockWatchlistService) Watch(ctx context.Context, user *model.User, symbol string) (*model.Watchlist, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Watch", ctx, user, symbol)
+	ret0, _ := ret[0].(*model.Watchlist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Watch indicates an expected call of Watch.
+func (mr *MockWatchlistServiceMockRecorder) Watch(ctx, user, symbol interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockWatchlistService)(nil).Watch), ctx, user, symbol)
+}
@@ -3,21 +3,29 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"server/internal/model"
 	"server/internal/repo"
-	"sort"
 )
 
 const (
-	userJoinMessage  = "<SayHi>"
-	userLeaveMessage = "<SayBye>"
-	postsLimit       = 50
+	postsLimit = 50
+	pageLimit  = 100
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrBothCursors   = errors.New("before and after cannot be used together")
 )
 
 type PostService interface {
-	CreatePost(ctx context.Context, post *model.Post, broadcast chan []byte) error
+	CreatePost(ctx context.Context, post *model.Post, broadcast chan *model.Broadcast) error
+	GetHistory(ctx context.Context, roomID string, limit int) ([]*model.Post, error)
+	GetPosts(ctx context.Context, roomID string, before string, after string, limit int) (*model.PostPage, error)
+	UserJoined(user *model.User, roomID string, broadcast chan *model.Broadcast)
+	UserLeft(user *model.User, roomID string, broadcast chan *model.Broadcast)
 }
 
 type postService struct {
@@ -31,43 +39,126 @@ func NewPostService(repo repo.PostRepo) PostService {
 	}
 }
 
-// CreatePost inserts a new post into the database and sends the updated post list to the broadcast channel
-func (s *postService) CreatePost(ctx context.Context, post *model.Post, broadcast chan []byte) error {
-	if post.Message == userJoinMessage {
-		post.Message = fmt.Sprintf("%s joined the chatroom!", post.User.Username)
-	}
-
-	if post.Message == userLeaveMessage {
-		post.Message = fmt.Sprintf("%s left the chatroom!", post.User.Username)
-	}
-
+// CreatePost inserts a new post into the database and sends it to the broadcast channel of its room
+func (s *postService) CreatePost(ctx context.Context, post *model.Post, broadcast chan *model.Broadcast) error {
 	post, err := s.Repo.CreatePost(ctx, post)
 	if err != nil {
 		return err
 	}
 
-	broadcastPosts(s.Repo, broadcast)
+	broadcastEvent(broadcast, post.RoomID, model.EventPostCreated, post)
 
 	return nil
 }
 
-// broadcastPosts sends the merged list of posts + commands to the broadcast channel
-func broadcastPosts(repo repo.PostRepo, broadcast chan []byte) {
-	posts, err := repo.GetRecentPosts(context.Background(), postsLimit)
+// GetHistory returns the last <limit> posts of a room, newest first, limit is capped to postsLimit
+func (s *postService) GetHistory(ctx context.Context, roomID string, limit int) ([]*model.Post, error) {
+	if limit <= 0 || limit > postsLimit {
+		limit = postsLimit
+	}
+
+	return s.Repo.GetRecentPosts(ctx, roomID, limit)
+}
+
+// GetPosts returns a page of posts of a room, newest first, using keyset pagination on (timestamp, id)
+// before pages back through older posts, after fetches the posts missed since a cursor, without cursors the newest posts are returned
+// limit defaults to postsLimit and is capped to pageLimit
+func (s *postService) GetPosts(ctx context.Context, roomID string, before string, after string, limit int) (*model.PostPage, error) {
+	if before != "" && after != "" {
+		return nil, ErrBothCursors
+	}
+
+	if limit <= 0 {
+		limit = postsLimit
+	}
+	if limit > pageLimit {
+		limit = pageLimit
+	}
+
+	var (
+		posts []*model.Post
+		err   error
+	)
+
+	// one extra post is fetched to know if there are more posts past this page
+	switch {
+	case after != "":
+		cursor, cErr := model.ParseCursor(after)
+		if cErr != nil {
+			return nil, ErrInvalidCursor
+		}
+
+		posts, err = s.Repo.GetPostsAfter(ctx, roomID, cursor, limit+1)
+	case before != "":
+		cursor, cErr := model.ParseCursor(before)
+		if cErr != nil {
+			return nil, ErrInvalidCursor
+		}
+
+		posts, err = s.Repo.GetPostsBefore(ctx, roomID, cursor, limit+1)
+	default:
+		posts, err = s.Repo.GetRecentPosts(ctx, roomID, limit+1)
+	}
 	if err != nil {
-		log.Printf("error getting posts from database: %s", err)
+		return nil, errors.New(fmt.Sprintf("error getting posts: %s", err))
 	}
 
-	posts = append(posts, commands...)
-	sort.Slice(posts, func(i, j int) bool {
-		return posts[i].Timestamp.After(*posts[j].Timestamp)
-	})
+	page := &model.PostPage{
+		Posts:   posts,
+		Before:  before,
+		After:   after,
+		HasMore: len(posts) > limit,
+	}
+
+	if page.HasMore {
+		page.Posts = posts[:limit]
+	}
+
+	if after != "" {
+		// the posts after a cursor come oldest first
+		for i, j := 0, len(page.Posts)-1; i < j; i, j = i+1, j-1 {
+			page.Posts[i], page.Posts[j] = page.Posts[j], page.Posts[i]
+		}
+	}
+
+	if len(page.Posts) > 0 {
+		page.After = model.NewCursor(page.Posts[0]).String()
+		page.Before = model.NewCursor(page.Posts[len(page.Posts)-1]).String()
+	}
+
+	if page.Posts == nil {
+		page.Posts = []*model.Post{}
+	}
 
-	bPosts, err := json.Marshal(posts)
+	return page, nil
+}
+
+// UserJoined announces to the room that the user connected to it
+func (s *postService) UserJoined(user *model.User, roomID string, broadcast chan *model.Broadcast) {
+	broadcastEvent(broadcast, roomID, model.EventUserJoined, user)
+}
+
+// UserLeft announces to the room that the user disconnected from it
+func (s *postService) UserLeft(user *model.User, roomID string, broadcast chan *model.Broadcast) {
+	broadcastEvent(broadcast, roomID, model.EventUserLeft, user)
+}
+
+// broadcastEvent sends an event to the broadcast channel of a room
+func broadcastEvent(broadcast chan *model.Broadcast, roomID string, eventType string, data interface{}) {
+	event := &model.Event{
+		Type:   eventType,
+		RoomID: roomID,
+		Data:   data,
+	}
+
+	bEvent, err := json.Marshal(event)
 	if err != nil {
-		log.Printf("error marshaling posts: %s", err)
+		log.Printf("error marshaling event: %s", err)
 		return
 	}
 
-	broadcast <- bPosts
+	broadcast <- &model.Broadcast{
+		RoomID:  roomID,
+		Payload: bEvent,
+	}
 }
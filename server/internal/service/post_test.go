@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"server/internal/model"
+	mock_repo "server/internal/repo/mocks"
+	"testing"
+	"time"
+)
+
+func TestCreatePostBroadcastsOnlyTheNewPost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	post := &model.Post{
+		UserID:  "f1c21d1d-3411-4bfd-a99f-8fc52dc65bb5",
+		RoomID:  roomID,
+		Message: "Hello!",
+	}
+
+	mockPostRepo := mock_repo.NewMockPostRepo(ctrl)
+	mockPostRepo.EXPECT().CreatePost(gomock.Any(), post).Return(post, nil)
+
+	broadcast := make(chan *model.Broadcast, 1)
+
+	service := NewPostService(mockPostRepo)
+	err := service.CreatePost(context.Background(), post, broadcast)
+
+	assert.NoError(t, err)
+
+	m := <-broadcast
+	assert.Equal(t, roomID, m.RoomID)
+
+	var event struct {
+		Type string      `json:"type"`
+		Data *model.Post `json:"data"`
+	}
+	if err := json.Unmarshal(m.Payload, &event); err != nil {
+		t.Fatalf("Failed to unmarshal event: %s", err)
+	}
+
+	assert.Equal(t, model.EventPostCreated, event.Type)
+	assert.Equal(t, "Hello!", event.Data.Message)
+}
+
+func TestGetHistoryCapsTheLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	posts := []*model.Post{
+		{RoomID: roomID, Message: "Hello!"},
+	}
+
+	mockPostRepo := mock_repo.NewMockPostRepo(ctrl)
+	mockPostRepo.EXPECT().GetRecentPosts(gomock.Any(), roomID, 10).Return(posts, nil)
+	mockPostRepo.EXPECT().GetRecentPosts(gomock.Any(), roomID, postsLimit).Return(posts, nil).Times(2)
+
+	service := NewPostService(mockPostRepo)
+
+	for _, limit := range []int{10, 0, 1000} {
+		history, err := service.GetHistory(context.Background(), roomID, limit)
+
+		assert.NoError(t, err)
+		assert.True(t, containsMessage(history, "Hello!"))
+	}
+}
+
+func TestGetPostsPaginatesWithCursors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now().UTC()
+	older := now.Add(-time.Minute)
+	oldest := now.Add(-time.Hour)
+
+	posts := []*model.Post{
+		{ID: uuid.New(), RoomID: roomID, Message: "Newest", Timestamp: &now},
+		{ID: uuid.New(), RoomID: roomID, Message: "Older", Timestamp: &older},
+		{ID: uuid.New(), RoomID: roomID, Message: "Oldest", Timestamp: &oldest},
+	}
+	before := model.NewCursor(posts[0])
+
+	mockPostRepo := mock_repo.NewMockPostRepo(ctrl)
+	mockPostRepo.EXPECT().GetPostsBefore(gomock.Any(), roomID, before, 2).Return(posts[1:], nil)
+
+	service := NewPostService(mockPostRepo)
+
+	page, err := service.GetPosts(context.Background(), roomID, before.String(), "", 1)
+
+	assert.NoError(t, err)
+	assert.True(t, page.HasMore)
+	assert.Len(t, page.Posts, 1)
+	assert.Equal(t, "Older", page.Posts[0].Message)
+	assert.Equal(t, model.NewCursor(posts[1]).String(), page.Before)
+
+	_, err = service.GetPosts(context.Background(), roomID, "not-a-cursor", "", 1)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = service.GetPosts(context.Background(), roomID, before.String(), before.String(), 1)
+	assert.ErrorIs(t, err, ErrBothCursors)
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"server/internal/model"
+	"sort"
+	"sync"
+)
+
+// Command is a chat command, sent as /<name>=<args> or just /<name> when it takes no arguments
+type Command interface {
+	// Name is the keyword of the command, such as stock for /stock=aapl.us
+	Name() string
+	// Usage is an example of a valid invocation, shown when the command is malformed
+	Usage() string
+	// Help describes what the command does
+	Help() string
+	// Parse validates the arguments of the command and returns them normalized
+	Parse(args string) (string, error)
+	// Remote reports whether the command is answered by the bot, remote commands are handled asynchronously
+	// and their answer is broadcast to the room when it arrives
+	Remote() bool
+	// Handle runs the command with its parsed arguments and returns the reply for the requester, if any
+	Handle(ctx context.Context, req *CommandRequest) (string, error)
+}
+
+// CommandRequest is a command invocation, the post is the message carrying the command
+type CommandRequest struct {
+	Args string
+	Post *model.Post
+}
+
+// CommandRegistry keeps the commands by name
+type CommandRegistry struct {
+	mu       sync.RWMutex
+	commands map[string]Command
+}
+
+// NewCommandRegistry builds a registry with the given commands
+func NewCommandRegistry(commands ...Command) *CommandRegistry {
+	r := &CommandRegistry{
+		commands: make(map[string]Command),
+	}
+
+	for _, c := range commands {
+		r.Register(c)
+	}
+
+	return r
+}
+
+// Register adds a command to the registry, replacing any command with the same name
+func (r *CommandRegistry) Register(c Command) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.commands[c.Name()] = c
+}
+
+// Lookup returns the command with the given name
+func (r *CommandRegistry) Lookup(name string) (Command, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.commands[name]
+	return c, ok
+}
+
+// Commands returns all the registered commands sorted by name
+func (r *CommandRegistry) Commands() []Command {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	commands := make([]Command, 0, len(r.commands))
+	for _, c := range r.commands {
+		commands = append(commands, c)
+	}
+
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Name() < commands[j].Name()
+	})
+
+	return commands
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"server/internal/model"
+	"server/internal/repo"
+	"strings"
+)
+
+var (
+	ErrRoomNotFound    = errors.New("room not found")
+	ErrInvalidRoomName = errors.New("room name cannot be empty")
+)
+
+type RoomService interface {
+	CreateRoom(ctx context.Context, room *model.Room, user *model.User) (*model.Room, error)
+	GetRooms(ctx context.Context) ([]*model.Room, error)
+	JoinRoom(ctx context.Context, roomID string, user *model.User) (*model.Room, error)
+	IsMember(ctx context.Context, roomID string, user *model.User) (bool, error)
+}
+
+type roomService struct {
+	Repo repo.RoomRepo
+}
+
+// NewRoomService builds a service and injects its dependencies
+func NewRoomService(repo repo.RoomRepo) RoomService {
+	return &roomService{
+		Repo: repo,
+	}
+}
+
+// CreateRoom inserts a new room into the database and makes its creator a member of it
+func (s *roomService) CreateRoom(ctx context.Context, room *model.Room, user *model.User) (*model.Room, error) {
+	room.Name = strings.TrimSpace(room.Name)
+	if room.Name == "" {
+		return nil, ErrInvalidRoomName
+	}
+
+	room.CreatedBy = user.ID.String()
+
+	room, err := s.Repo.CreateRoom(ctx, room)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Repo.AddMember(ctx, room.ID.String(), user.ID.String()); err != nil {
+		return nil, err
+	}
+
+	return room, nil
+}
+
+// GetRooms returns all the available rooms
+func (s *roomService) GetRooms(ctx context.Context) ([]*model.Room, error) {
+	return s.Repo.GetRooms(ctx)
+}
+
+// JoinRoom makes the user a member of an existing room
+func (s *roomService) JoinRoom(ctx context.Context, roomID string, user *model.User) (*model.Room, error) {
+	if _, err := uuid.Parse(roomID); err != nil {
+		return nil, ErrRoomNotFound
+	}
+
+	room, err := s.Repo.GetRoom(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	if err := s.Repo.AddMember(ctx, roomID, user.ID.String()); err != nil {
+		return nil, err
+	}
+
+	return room, nil
+}
+
+// IsMember reports whether the user joined the room
+func (s *roomService) IsMember(ctx context.Context, roomID string, user *model.User) (bool, error) {
+	if _, err := uuid.Parse(roomID); err != nil {
+		return false, nil
+	}
+
+	return s.Repo.IsMember(ctx, roomID, user.ID.String())
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"server/internal/model"
+	"time"
+)
+
+const (
+	tokenIssuer   = "stockchat"
+	tokenDuration = 24 * time.Hour
+)
+
+type TokenService interface {
+	IssueToken(user *model.User) (string, error)
+	ValidateToken(token string) (*model.User, error)
+}
+
+type tokenService struct {
+	Secret []byte
+}
+
+type sessionClaims struct {
+	Username string `json:"username"`
+	jwt.RegisteredClaims
+}
+
+// NewTokenService builds a service that signs session tokens with the given secret
+func NewTokenService(secret string) TokenService {
+	return &tokenService{
+		Secret: []byte(secret),
+	}
+}
+
+// IssueToken returns a signed session token carrying the user identity
+func (s *tokenService) IssueToken(user *model.User) (string, error) {
+	now := time.Now().UTC()
+
+	claims := sessionClaims{
+		Username: user.Username,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tokenIssuer,
+			Subject:   user.ID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(tokenDuration)),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.Secret)
+	if err != nil {
+		return "", errors.New(fmt.Sprintf("error signing token: %s", err))
+	}
+
+	return token, nil
+}
+
+// ValidateToken checks the token signature and expiration and returns the user it was issued for
+func (s *tokenService) ValidateToken(token string) (*model.User, error) {
+	claims := &sessionClaims{}
+
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return s.Secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}), jwt.WithIssuer(tokenIssuer))
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid token: %s", err))
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid token subject: %s", err))
+	}
+
+	return &model.User{
+		ID:       userID,
+		Username: claims.Username,
+	}, nil
+}
@@ -10,18 +10,24 @@ import (
 	"server/internal/repo"
 )
 
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
 type UserService interface {
 	CreateUser(ctx context.Context, user *model.User) (*model.User, error)
-	LoginUser(ctx context.Context, user *model.User) *model.User
+	LoginUser(ctx context.Context, user *model.User) (*model.Session, error)
 }
 
 type userService struct {
-	Repo repo.UserRepo
+	Repo         repo.UserRepo
+	TokenService TokenService
 }
 
 // NewUserService builds a service and injects its dependencies
-func NewUserService(repo repo.UserRepo) UserService {
-	return &userService{Repo: repo}
+func NewUserService(repo repo.UserRepo, ts TokenService) UserService {
+	return &userService{
+		Repo:         repo,
+		TokenService: ts,
+	}
 }
 
 // CreateUser inserts a new user into the database
@@ -36,18 +42,28 @@ func (s *userService) CreateUser(ctx context.Context, user *model.User) (*model.
 	return s.Repo.CreateUser(ctx, user)
 }
 
-// LoginUser queries a user using username and password and returns it if found
-func (s *userService) LoginUser(ctx context.Context, user *model.User) *model.User {
+// LoginUser queries a user using username and password and returns a signed session for it if found
+func (s *userService) LoginUser(ctx context.Context, user *model.User) (*model.Session, error) {
 	dbUser, err := s.Repo.GetUserByName(ctx, user)
 	if err != nil {
-		dbUser = &model.User{}
 		log.Printf("error getting the user from the database: %s", err)
+		return nil, ErrInvalidCredentials
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(dbUser.Password), []byte(user.Password)); err != nil {
-		dbUser = &model.User{}
 		log.Printf("password does not match: %s", err)
+		return nil, ErrInvalidCredentials
+	}
+
+	dbUser.Password = ""
+
+	token, err := s.TokenService.IssueToken(dbUser)
+	if err != nil {
+		return nil, err
 	}
 
-	return dbUser
+	return &model.Session{
+		Token: token,
+		User:  dbUser,
+	}, nil
 }
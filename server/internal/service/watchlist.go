@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"server/internal/model"
+	"server/internal/repo"
+)
+
+// maxWatchlistSymbols is the number of stock codes a user can watch
+// the /watchlist command quotes all of them with a single request, so it is the limit of codes of a request
+const maxWatchlistSymbols = maxStockCodes
+
+var (
+	ErrInvalidSymbol = errors.New("invalid stock code")
+	ErrWatchlistFull = errors.New(fmt.Sprintf("a watchlist cannot have more than %d stock codes", maxWatchlistSymbols))
+	ErrNotWatched    = errors.New("stock code not in the watchlist")
+)
+
+type WatchlistService interface {
+	GetWatchlist(ctx context.Context, user *model.User) (*model.Watchlist, error)
+	Watch(ctx context.Context, user *model.User, symbol string) (*model.Watchlist, error)
+	Unwatch(ctx context.Context, user *model.User, symbol string) (*model.Watchlist, error)
+}
+
+type watchlistService struct {
+	Repo repo.WatchlistRepo
+}
+
+// NewWatchlistService builds a service and injects its dependencies
+func NewWatchlistService(repo repo.WatchlistRepo) WatchlistService {
+	return &watchlistService{
+		Repo: repo,
+	}
+}
+
+// GetWatchlist returns the stock codes watched by the user
+func (s *watchlistService) GetWatchlist(ctx context.Context, user *model.User) (*model.Watchlist, error) {
+	symbols, err := s.Repo.GetSymbols(ctx, user.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Watchlist{Symbols: symbols}, nil
+}
+
+// Watch adds a stock code to the watchlist of the user, watching a code twice has no effect
+func (s *watchlistService) Watch(ctx context.Context, user *model.User, symbol string) (*model.Watchlist, error) {
+	symbol, err := parseSymbol(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	// the repository checks the limit and inserts the code in a single transaction
+	if _, err := s.Repo.AddSymbol(ctx, user.ID.String(), symbol, maxWatchlistSymbols); err != nil {
+		if errors.Is(err, repo.ErrWatchlistFull) {
+			return nil, ErrWatchlistFull
+		}
+		return nil, err
+	}
+
+	return s.GetWatchlist(ctx, user)
+}
+
+// Unwatch removes a stock code from the watchlist of the user
+func (s *watchlistService) Unwatch(ctx context.Context, user *model.User, symbol string) (*model.Watchlist, error) {
+	symbol, err := parseSymbol(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	removed, err := s.Repo.RemoveSymbol(ctx, user.ID.String(), symbol)
+	if err != nil {
+		return nil, err
+	}
+	if !removed {
+		return nil, ErrNotWatched
+	}
+
+	return s.GetWatchlist(ctx, user)
+}
+
+// parseSymbol validates a single stock code and returns it in lower case
+func parseSymbol(symbol string) (string, error) {
+	codes, err := parseStockCodes(symbol)
+	if err != nil || len(codes) != 1 {
+		return "", ErrInvalidSymbol
+	}
+
+	return codes[0], nil
+}
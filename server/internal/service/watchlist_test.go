@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"server/internal/infra"
+	mock_infra "server/internal/infra/mocks"
+	"server/internal/model"
+	"server/internal/repo"
+	mock_repo "server/internal/repo/mocks"
+	"testing"
+)
+
+func TestWatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := &model.User{ID: uuid.MustParse("f1c21d1d-3411-4bfd-a99f-8fc52dc65bb5"), Username: "Alice"}
+
+	mockRepo := mock_repo.NewMockWatchlistRepo(ctrl)
+	gomock.InOrder(
+		mockRepo.EXPECT().AddSymbol(gomock.Any(), user.ID.String(), "msft.us", maxWatchlistSymbols).Return(true, nil),
+		mockRepo.EXPECT().GetSymbols(gomock.Any(), user.ID.String()).Return([]string{"aapl.us", "msft.us"}, nil),
+		mockRepo.EXPECT().AddSymbol(gomock.Any(), user.ID.String(), "aapl.us", maxWatchlistSymbols).Return(false, nil),
+		mockRepo.EXPECT().GetSymbols(gomock.Any(), user.ID.String()).Return([]string{"aapl.us", "msft.us"}, nil),
+	)
+
+	service := NewWatchlistService(mockRepo)
+
+	watchlist, err := service.Watch(context.Background(), user, " MSFT.US ")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"aapl.us", "msft.us"}, watchlist.Symbols)
+
+	// watching a stock twice has no effect
+	watchlist, err = service.Watch(context.Background(), user, "aapl.us")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"aapl.us", "msft.us"}, watchlist.Symbols)
+
+	_, err = service.Watch(context.Background(), user, "aapl.us,msft.us")
+	assert.ErrorIs(t, err, ErrInvalidSymbol)
+}
+
+func TestWatchIsLimitedToTheCodesOfARequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := &model.User{ID: uuid.MustParse("f1c21d1d-3411-4bfd-a99f-8fc52dc65bb5"), Username: "Alice"}
+
+	mockRepo := mock_repo.NewMockWatchlistRepo(ctrl)
+	mockRepo.EXPECT().AddSymbol(gomock.Any(), user.ID.String(), "aapl.us", maxStockCodes).Return(false, repo.ErrWatchlistFull)
+
+	_, err := NewWatchlistService(mockRepo).Watch(context.Background(), user, "aapl.us")
+	assert.ErrorIs(t, err, ErrWatchlistFull)
+}
+
+func TestExecuteWatchlistCommand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := &model.User{ID: uuid.MustParse("f1c21d1d-3411-4bfd-a99f-8fc52dc65bb5"), Username: "Alice"}
+
+	published := make(chan []byte, 1)
+
+	mockAMQP := mock_infra.NewMockAMQPClient(ctrl)
+	mockAMQP.EXPECT().PublishAMQMessage(gomock.Any(), infra.StockKey, gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, key string, body []byte, correlationID string) error {
+		_, bounded := ctx.Deadline()
+		assert.True(t, bounded, "Expected the request to be published with a deadline")
+		published <- body
+		return nil
+	})
+
+	mockRepo := mock_repo.NewMockWatchlistRepo(ctrl)
+	gomock.InOrder(
+		mockRepo.EXPECT().GetSymbols(gomock.Any(), user.ID.String()).Return([]string{}, nil),
+		mockRepo.EXPECT().GetSymbols(gomock.Any(), user.ID.String()).Return([]string{"aapl.us", "msft.us"}, nil),
+	)
+
+	service := NewCommandService(&mock_repo.MockPostRepo{}, &mock_repo.MockAlertRepo{}, NewWatchlistService(mockRepo), mockAMQP)
+	post := &model.Post{UserID: user.ID.String(), User: user, RoomID: roomID, Message: "/watchlist"}
+
+	reply := service.ExecuteCommand(context.Background(), post)
+	assert.NotNil(t, reply)
+	assert.Equal(t, "Your watchlist is empty, add stocks to it with /watch=aapl.us", reply.Message)
+
+	// the watched stocks are requested at once, and the bot answers them to the room
+	reply = service.ExecuteCommand(context.Background(), post)
+	assert.Nil(t, reply)
+	assert.Contains(t, string(<-published), `"stockCodes":["aapl.us","msft.us"]`)
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"server/internal/infra"
+	"strings"
+)
+
+// watchCommand adds a stock to the watchlist of the requester
+type watchCommand struct {
+	service *commandService
+}
+
+func (c *watchCommand) Name() string  { return "watch" }
+func (c *watchCommand) Usage() string { return "/watch=aapl.us" }
+func (c *watchCommand) Help() string  { return "adds a stock to your watchlist" }
+func (c *watchCommand) Remote() bool  { return false }
+
+// Parse expects a single stock code
+func (c *watchCommand) Parse(args string) (string, error) {
+	return parseSymbol(args)
+}
+
+// Handle adds the stock code to the watchlist, explaining why it could not be added
+func (c *watchCommand) Handle(ctx context.Context, req *CommandRequest) (string, error) {
+	watchlist, err := c.service.Watchlist.Watch(ctx, req.Post.User, req.Args)
+	if errors.Is(err, ErrWatchlistFull) {
+		return fmt.Sprintf("Your watchlist already has %d stocks, remove some of them with /unwatch before adding new ones", maxWatchlistSymbols), nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s added to your watchlist, which has %d stocks", strings.ToUpper(req.Args), len(watchlist.Symbols)), nil
+}
+
+// unwatchCommand removes a stock from the watchlist of the requester
+type unwatchCommand struct {
+	service *commandService
+}
+
+func (c *unwatchCommand) Name() string  { return "unwatch" }
+func (c *unwatchCommand) Usage() string { return "/unwatch=aapl.us" }
+func (c *unwatchCommand) Help() string  { return "removes a stock from your watchlist" }
+func (c *unwatchCommand) Remote() bool  { return false }
+
+// Parse expects a single stock code
+func (c *unwatchCommand) Parse(args string) (string, error) {
+	return parseSymbol(args)
+}
+
+// Handle removes the stock code from the watchlist
+func (c *unwatchCommand) Handle(ctx context.Context, req *CommandRequest) (string, error) {
+	_, err := c.service.Watchlist.Unwatch(ctx, req.Post.User, req.Args)
+	if errors.Is(err, ErrNotWatched) {
+		return fmt.Sprintf("%s is not in your watchlist, type /watchlist to check it", strings.ToUpper(req.Args)), nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s removed from your watchlist", strings.ToUpper(req.Args)), nil
+}
+
+// watchlistCommand requests the quotes of every stock in the watchlist of the requester to the bot
+type watchlistCommand struct {
+	service *commandService
+}
+
+func (c *watchlistCommand) Name() string  { return "watchlist" }
+func (c *watchlistCommand) Usage() string { return "/watchlist" }
+func (c *watchlistCommand) Help() string {
+	return "gets the quotes of all the stocks in your watchlist"
+}
+
+// Remote is true because the quotes are answered by the bot, an empty watchlist is still answered locally
+func (c *watchlistCommand) Remote() bool { return true }
+
+// Parse accepts no arguments
+func (c *watchlistCommand) Parse(args string) (string, error) {
+	if args != "" {
+		return "", errors.New("watchlist takes no arguments")
+	}
+
+	return "", nil
+}
+
+// Handle publishes a single quote request with every watched stock, its table is broadcast to the room when the bot answers it
+func (c *watchlistCommand) Handle(ctx context.Context, req *CommandRequest) (string, error) {
+	watchlist, err := c.service.Watchlist.GetWatchlist(ctx, req.Post.User)
+	if err != nil {
+		return "", err
+	}
+
+	if len(watchlist.Symbols) == 0 {
+		return "Your watchlist is empty, add stocks to it with /watch=aapl.us", nil
+	}
+
+	pl := &stockPayload{
+		StockCodes: watchlist.Symbols,
+	}
+
+	return "", c.service.requestBot(ctx, infra.StockKey, pl, req.Post)
+}